@@ -0,0 +1,158 @@
+// Package audit 核对 Counter 合约的状态：x 在任意区块的值应等于部署以来所有 Increment 事件 by 之和。
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"sepolia-block/indexer"
+)
+
+// XReader 读取指定区块的 x，*counter.CounterCaller 和 *counter.Counter 都满足
+type XReader interface {
+	X(opts *bind.CallOpts) (*big.Int, error)
+}
+
+// Summer 汇总区块 [from, to] 内 Increment 事件的 by 之和与事件数
+type Summer func(ctx context.Context, from, to uint64) (sum *big.Int, events int, err error)
+
+// Range 一个区块区间的核对结果
+type Range struct {
+	From, To uint64
+	Before   *big.Int // x 在 From-1 的值，部署前为 0
+	After    *big.Int // x 在 To 的值
+	Sum      *big.Int // 区间内事件 by 之和
+	Events   int
+}
+
+// Delta x 在区间内的变化
+func (r Range) Delta() *big.Int {
+	return new(big.Int).Sub(r.After, r.Before)
+}
+
+// OK x 的变化与事件之和一致
+func (r Range) OK() bool {
+	return r.Delta().Cmp(r.Sum) == 0
+}
+
+// Auditor 逐段比较 x 的变化与事件之和
+type Auditor struct {
+	reader XReader
+	sum    Summer
+}
+
+// New 创建 Auditor。读取历史区块的 x 需要归档节点。
+func New(reader XReader, sum Summer) *Auditor {
+	return &Auditor{reader: reader, sum: sum}
+}
+
+// Audit 把区块 [from, to] 按 step 个区块分段核对。from 通常是部署区块，
+// 此时 x 的初始值为 0；从中途开始时以 from-1 的 x 为初始值。
+// onRange 可选，每段核对完成后回调，便于长区间边核对边输出。
+func (a *Auditor) Audit(ctx context.Context, from, to, step uint64, onRange func(Range)) ([]Range, error) {
+	if step == 0 {
+		return nil, errors.New("step must be positive")
+	}
+	before := new(big.Int)
+	if from > 0 {
+		x, err := a.x(ctx, from-1)
+		if err != nil {
+			return nil, err
+		}
+		before = x
+	}
+	var ranges []Range
+	for start := from; start <= to; {
+		end := min(start+step-1, to)
+		after, err := a.x(ctx, end)
+		if err != nil {
+			return ranges, err
+		}
+		sum, n, err := a.sum(ctx, start, end)
+		if err != nil {
+			return ranges, fmt.Errorf("sum events in blocks %d-%d: %w", start, end, err)
+		}
+		r := Range{From: start, To: end, Before: before, After: after, Sum: sum, Events: n}
+		ranges = append(ranges, r)
+		if onRange != nil {
+			onRange(r)
+		}
+		before = after
+		if end == to {
+			break
+		}
+		start = end + 1
+	}
+	return ranges, nil
+}
+
+// x 读取区块 block 的 x，合约尚未部署时为 0
+func (a *Auditor) x(ctx context.Context, block uint64) (*big.Int, error) {
+	x, err := a.reader.X(&bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(block)})
+	if errors.Is(err, bind.ErrNoCode) {
+		return new(big.Int), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read x at block %d: %w", block, err)
+	}
+	return x, nil
+}
+
+// FilterSum 通过 FilterIncrement 向节点查询事件，结果过多时把区间对半拆分
+func FilterSum(filterer indexer.IncrementFilterer) Summer {
+	var sum Summer
+	sum = func(ctx context.Context, from, to uint64) (*big.Int, int, error) {
+		it, err := filterer.FilterIncrement(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+		if indexer.IsTooManyResults(err) && to > from {
+			mid := from + (to-from)/2
+			lo, n1, err := sum(ctx, from, mid)
+			if err != nil {
+				return nil, 0, err
+			}
+			hi, n2, err := sum(ctx, mid+1, to)
+			if err != nil {
+				return nil, 0, err
+			}
+			return lo.Add(lo, hi), n1 + n2, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		defer it.Close()
+
+		total, n := new(big.Int), 0
+		for it.Next() {
+			total.Add(total, it.Event.By)
+			n++
+		}
+		return total, n, it.Error()
+	}
+	return sum
+}
+
+// StoreSum 从本地索引汇总事件，用于核对索引本身。区间超出已索引位置时返回错误。
+func StoreSum(store *indexer.Store, chainID *big.Int, address common.Address) Summer {
+	return func(ctx context.Context, from, to uint64) (*big.Int, int, error) {
+		last, ok, err := store.Last(chainID, address)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !ok || last < to {
+			return nil, 0, fmt.Errorf("index does not cover block %d", to)
+		}
+		events, err := store.Events(chainID, address, from, to)
+		if err != nil {
+			return nil, 0, err
+		}
+		total := new(big.Int)
+		for _, ev := range events {
+			total.Add(total, ev.By)
+		}
+		return total, len(events), nil
+	}
+}
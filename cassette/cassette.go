@@ -0,0 +1,295 @@
+// Package cassette 录制和回放 JSON-RPC 调用，用于离线测试。
+//
+// Recorder 包在 HTTP 连接外层，把每次调用的方法、参数和节点的应答逐行写入 cassette 文件（NDJSON）；
+// Replayer 不连接网络，按方法和参数从 cassette 中取出应答。同一调用录到多个应答时（例如轮询
+// eth_blockNumber）按录制顺序依次返回，用完后一直返回最后一个，因此回放结果与录制时一致。
+// 只有 HTTP 连接可以录制，WebSocket 和 IPC 订阅不在其中。
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNotRecorded 回放时 cassette 中没有对应的调用
+var ErrNotRecorded = errors.New("cassette: call not recorded")
+
+// Interaction 一次 JSON-RPC 调用及其应答，Result 和 Error 二者有其一
+type Interaction struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+}
+
+// Error 节点返回的 JSON-RPC 错误
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// key 匹配用的键：方法加上去掉空白的参数
+func (i *Interaction) key() string {
+	return callKey(i.Method, i.Params)
+}
+
+func callKey(method string, params json.RawMessage) string {
+	var buf bytes.Buffer
+	if len(params) > 0 && json.Compact(&buf, params) != nil {
+		buf.Reset()
+		buf.Write(params)
+	}
+	return method + " " + buf.String()
+}
+
+// Load 读取 cassette 文件
+func Load(path string) ([]Interaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Interaction
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 64<<20) // 区块和日志的应答可能很大
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var i Interaction
+		if err := json.Unmarshal(sc.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		out = append(out, i)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// message JSON-RPC 请求或应答，单个和批量请求都拆成 message 处理
+type message struct {
+	JSONRPC string          `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// parseMessages 解析单个或批量的 JSON-RPC 消息
+func parseMessages(data []byte) (msgs []message, batch bool, err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &msgs)
+		return msgs, true, err
+	}
+	var m message
+	err = json.Unmarshal(data, &m)
+	return []message{m}, false, err
+}
+
+// Recorder 把经过的 JSON-RPC 调用追加写入 cassette 文件，每条记录立即写入，中途退出也不丢失已录制的调用
+type Recorder struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewRecorder 创建（或清空）cassette 文件开始录制
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{f: f}, nil
+}
+
+// Close 关闭 cassette 文件
+func (r *Recorder) Close() error {
+	return r.f.Close()
+}
+
+// Wrap 返回录制经过 base 的调用的 http.RoundTripper，可以包在多个节点的连接外层
+func (r *Recorder) Wrap(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordTransport{base: base, rec: r}
+}
+
+type recordTransport struct {
+	base http.RoundTripper
+	rec  *Recorder
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err // 连接错误和 HTTP 错误不录制
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if err := t.rec.record(body, data); err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+	return resp, nil
+}
+
+// record 按 id 配对请求和应答，按请求顺序写入
+func (r *Recorder) record(reqData, respData []byte) error {
+	reqs, _, err := parseMessages(reqData)
+	if err != nil {
+		return err
+	}
+	resps, _, err := parseMessages(respData)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]message, len(resps))
+	for _, m := range resps {
+		byID[string(m.ID)] = m
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, req := range reqs {
+		resp, ok := byID[string(req.ID)]
+		if !ok {
+			continue
+		}
+		i := Interaction{Method: req.Method, Params: req.Params, Result: resp.Result, Error: resp.Error}
+		if err := enc.Encode(&i); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.f.Write(buf.Bytes())
+	return err
+}
+
+// Replayer 按 cassette 应答 JSON-RPC 请求的 http.RoundTripper，不访问网络
+type Replayer struct {
+	mu    sync.Mutex
+	calls map[string]*replies
+}
+
+type replies struct {
+	list []Interaction
+	next int
+}
+
+// NewReplayer 用录制的调用创建 Replayer
+func NewReplayer(interactions []Interaction) *Replayer {
+	r := &Replayer{calls: make(map[string]*replies)}
+	for _, i := range interactions {
+		k := i.key()
+		if r.calls[k] == nil {
+			r.calls[k] = new(replies)
+		}
+		r.calls[k].list = append(r.calls[k].list, i)
+	}
+	return r
+}
+
+// reply 取出下一个应答，录制的应答用完后重复最后一个
+func (r *Replayer) reply(method string, params json.RawMessage) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rs := r.calls[callKey(method, params)]
+	if rs == nil {
+		return Interaction{}, false
+	}
+	i := rs.list[rs.next]
+	if rs.next < len(rs.list)-1 {
+		rs.next++
+	}
+	return i, true
+}
+
+// RoundTrip 应答单个或批量请求，任何一个调用没有录制时返回 ErrNotRecorded
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	reqs, batch, err := parseMessages(body)
+	if err != nil {
+		return nil, err
+	}
+	resps := make([]message, len(reqs))
+	for n, m := range reqs {
+		i, ok := r.reply(m.Method, m.Params)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, m.Method, m.Params)
+		}
+		resps[n] = message{JSONRPC: "2.0", ID: m.ID, Result: i.Result, Error: i.Error}
+		if i.Error == nil && i.Result == nil {
+			resps[n].Result = json.RawMessage("null")
+		}
+	}
+
+	var data []byte
+	if batch {
+		data, err = json.Marshal(resps)
+	} else {
+		data, err = json.Marshal(resps[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// Dial 打开 cassette 文件，返回从中回放的 RPC 连接
+func Dial(ctx context.Context, path string) (*rpc.Client, error) {
+	interactions, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: NewReplayer(interactions)}
+	// 地址只用于构造请求，不会被访问
+	return rpc.DialOptions(ctx, "http://cassette.invalid", rpc.WithHTTPClient(client))
+}
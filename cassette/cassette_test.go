@@ -0,0 +1,274 @@
+package cassette_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"sepolia-block/cassette"
+	"sepolia-block/counter"
+)
+
+var record = flag.String("record", "", "record TestReplaySession against this RPC endpoint (e.g. anvil) instead of replaying testdata/session.ndjson")
+
+// stubNode 最小的 JSON-RPC 节点：区块号每次调用加一，eth_call 返回执行错误
+func stubNode(t *testing.T) *httptest.Server {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		head uint64 = 100
+	)
+	handle := func(req map[string]json.RawMessage) map[string]any {
+		resp := map[string]any{"jsonrpc": "2.0", "id": req["id"]}
+		var method string
+		json.Unmarshal(req["method"], &method)
+		switch method {
+		case "eth_blockNumber":
+			mu.Lock()
+			head++
+			resp["result"] = hexutil.EncodeUint64(head)
+			mu.Unlock()
+		case "eth_call":
+			resp["error"] = map[string]any{"code": 3, "message": "execution reverted", "data": "0x"}
+		default:
+			resp["result"] = "0x539"
+		}
+		return resp
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		var batch []map[string]json.RawMessage
+		if json.Unmarshal(raw, &batch) == nil {
+			resps := make([]map[string]any, len(batch))
+			for i, req := range batch {
+				resps[i] = handle(req)
+			}
+			json.NewEncoder(w).Encode(resps)
+			return
+		}
+		var req map[string]json.RawMessage
+		json.Unmarshal(raw, &req)
+		json.NewEncoder(w).Encode(handle(req))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// session 依次调用的结果，录制和回放时应当相同
+type session struct {
+	heads   []uint64
+	batch   []string
+	callErr string
+}
+
+func runSession(t *testing.T, c *rpc.Client) session {
+	t.Helper()
+	ctx := context.Background()
+	client := ethclient.NewClient(c)
+	var s session
+	for range 2 {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.heads = append(s.heads, head)
+	}
+	batch := []rpc.BatchElem{
+		{Method: "eth_chainId", Result: new(string)},
+		{Method: "net_version", Result: new(string)},
+	}
+	if err := c.BatchCallContext(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range batch {
+		s.batch = append(s.batch, *e.Result.(*string))
+	}
+	_, err := client.CallContract(ctx, ethereum.CallMsg{}, nil)
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("CallContract: %v, want the node's JSON-RPC error", err)
+	}
+	s.callErr = rpcErr.Error()
+	return s
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.ndjson")
+	rec, err := cassette.NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := stubNode(t)
+	c, err := rpc.DialOptions(context.Background(), node.URL, rpc.WithHTTPClient(&http.Client{Transport: rec.Wrap(nil)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := runSession(t, c)
+	c.Close()
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if recorded.heads[0] == recorded.heads[1] {
+		t.Fatalf("stub returned the same head twice: %v", recorded.heads)
+	}
+
+	interactions, err := cassette.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interactions) != 5 {
+		t.Errorf("recorded %d interactions, want 5", len(interactions))
+	}
+	c, err = cassette.Dial(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	replayed := runSession(t, c)
+	if recorded.heads[0] != replayed.heads[0] || recorded.heads[1] != replayed.heads[1] ||
+		recorded.batch[0] != replayed.batch[0] || recorded.batch[1] != replayed.batch[1] || recorded.callErr != replayed.callErr {
+		t.Errorf("replayed %+v, recorded %+v", replayed, recorded)
+	}
+
+	// 录制的应答用完后重复最后一个；没有录制的调用报 ErrNotRecorded
+	client := ethclient.NewClient(c)
+	if head, err := client.BlockNumber(context.Background()); err != nil || head != recorded.heads[1] {
+		t.Errorf("BlockNumber after the recording ran out = %d, %v, want %d", head, err, recorded.heads[1])
+	}
+	if _, err := client.BalanceAt(context.Background(), common.Address{}, nil); !errors.Is(err, cassette.ErrNotRecorded) {
+		t.Errorf("BalanceAt: %v, want ErrNotRecorded", err)
+	}
+}
+
+// TestReplaySession 回放 testdata/session.ndjson：读取区块、部署 Counter、IncBy 并读回状态和事件。
+// 使用 -record <RPC 地址> 对 anvil 等本地节点重新录制（账户为 anvil/hardhat 的第一个测试账户）。
+func TestReplaySession(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join("testdata", "session.ndjson")
+	var (
+		c   *rpc.Client
+		rec *cassette.Recorder
+		err error
+	)
+	if *record != "" {
+		if rec, err = cassette.NewRecorder(path); err != nil {
+			t.Fatal(err)
+		}
+		defer rec.Close()
+		c, err = rpc.DialOptions(ctx, *record, rpc.WithHTTPClient(&http.Client{Transport: rec.Wrap(nil)}))
+	} else {
+		c, err = cassette.Dial(ctx, path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := ethclient.NewClient(c)
+	defer client.Close()
+	// 回放时录制的未打包回执直接依次返回，不必等待
+	var poll time.Duration
+	if *record != "" {
+		poll = 100 * time.Millisecond
+	}
+
+	// 与 block 命令相同的读取
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(head))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.NumberU64() != head || block.Hash() != block.Header().Hash() {
+		t.Errorf("block %d hash %s does not match its header", block.NumberU64(), block.Hash())
+	}
+
+	// anvil/hardhat 公开的第一个测试账户
+	key, err := crypto.HexToECDSA("ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, tx, ctr, err := counter.DeployCounter(auth, client)
+	if err != nil {
+		t.Fatalf("DeployCounter: %v", err)
+	}
+	deployed := waitReceipt(t, client, tx, poll)
+	if deployed.ContractAddress != address {
+		t.Fatalf("receipt contract address %s, want %s", deployed.ContractAddress, address)
+	}
+	tx, err = ctr.IncBy(auth, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("IncBy: %v", err)
+	}
+	waitReceipt(t, client, tx, poll)
+
+	x, err := ctr.X(&bind.CallOpts{Context: ctx})
+	if err != nil || x.Int64() != 5 {
+		t.Errorf("X = %v, %v, want 5", x, err)
+	}
+	it, err := ctr.FilterIncrement(&bind.FilterOpts{Start: deployed.BlockNumber.Uint64(), Context: ctx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var events []int64
+	for it.Next() {
+		events = append(events, it.Event.By.Int64())
+	}
+	if len(events) != 1 || events[0] != 5 {
+		t.Errorf("Increment events %v, want [5]", events)
+	}
+}
+
+// waitReceipt 每隔 poll 查询回执直到交易打包，要求执行成功
+func waitReceipt(t *testing.T, client *ethclient.Client, tx *types.Transaction, poll time.Duration) *types.Receipt {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	for {
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		switch {
+		case err == nil && receipt.Status != types.ReceiptStatusSuccessful:
+			t.Fatalf("transaction %s reverted", tx.Hash())
+		case err == nil:
+			return receipt
+		case !errors.Is(err, ethereum.NotFound):
+			t.Fatalf("receipt of %s: %v", tx.Hash(), err)
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("transaction %s not mined", tx.Hash())
+		case <-time.After(poll):
+		}
+	}
+}
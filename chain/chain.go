@@ -0,0 +1,174 @@
+// Package chain 建立节点连接，并在签名前确认节点所在的链与配置一致，
+// 防止配错 RPC 时把交易签到（并重放到）错误的链上。
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"sepolia-block/failover"
+	"sepolia-block/signer"
+	"sepolia-block/transport"
+)
+
+// MismatchError 链 ID 与预期不一致：节点与配置不符，或要求签名的链不是已确认的链
+type MismatchError struct {
+	Expected *big.Int
+	Actual   *big.Int
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("chain ID mismatch: expected %s, got %s", e.Expected, e.Actual)
+}
+
+// Backend 命令使用的节点接口，*ethclient.Client 和多节点的 *failover.Backend 都满足
+type Backend interface {
+	bind.ContractBackend
+	bind.PendingContractCaller
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	Close()
+}
+
+// HealthInterval 多节点时后台健康检查的间隔
+const HealthInterval = 30 * time.Second
+
+// Client 已确认链 ID 的节点连接。eth_chainId 只在连接时查询一次，
+// ChainID 之后直接返回缓存值。
+type Client struct {
+	Backend
+	chainID *big.Int
+}
+
+// Dial 连接节点并校验链 ID，expected 为 0 时只查询不校验。
+// url 可以是逗号分隔的多个节点，此时通过 failover.Backend 在节点间切换，交易广播到所有节点。
+// 每个 HTTP 节点按 opts 重试和限流。
+func Dial(ctx context.Context, url string, expected uint64, opts transport.Options) (*Client, error) {
+	var (
+		backend Backend
+		err     error
+	)
+	dial := func(ctx context.Context, url string) (*ethclient.Client, error) {
+		rc, err := transport.Dial(ctx, url, opts)
+		if err != nil {
+			return nil, err
+		}
+		return ethclient.NewClient(rc), nil
+	}
+	if urls := splitURLs(url); len(urls) > 1 {
+		var fb *failover.Backend
+		if fb, err = failover.Dial(ctx, urls, expected, dial); err == nil {
+			fb.Start(HealthInterval)
+			backend = fb
+		}
+	} else {
+		backend, err = dial(ctx, url)
+	}
+	if err != nil {
+		return nil, err
+	}
+	client, err := New(ctx, backend, expected)
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func splitURLs(s string) []string {
+	var urls []string
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// New 在已有连接上查询并校验链 ID
+func New(ctx context.Context, c Backend, expected uint64) (*Client, error) {
+	// 使用 eth_chainId 而不是 net_version，后者是网络 ID，与签名无关
+	id, err := c.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("query chain ID: %w", err)
+	}
+	if expected != 0 && (!id.IsUint64() || id.Uint64() != expected) {
+		return nil, &MismatchError{Expected: new(big.Int).SetUint64(expected), Actual: id}
+	}
+	return &Client{Backend: c, chainID: id}, nil
+}
+
+// ID 返回已确认的链 ID
+func (c *Client) ID() *big.Int {
+	return new(big.Int).Set(c.chainID)
+}
+
+// ChainID 返回缓存的链 ID，不再访问节点
+func (c *Client) ChainID(context.Context) (*big.Int, error) {
+	return c.ID(), nil
+}
+
+// Guard 包装签名器，只允许为本连接确认过的链签名
+func (c *Client) Guard(s signer.Signer) signer.Signer {
+	return &guardedSigner{Signer: s, chainID: c.ID()}
+}
+
+type guardedSigner struct {
+	signer.Signer
+	chainID *big.Int
+}
+
+func (g *guardedSigner) check(chainID *big.Int) error {
+	if chainID == nil || chainID.Cmp(g.chainID) != 0 {
+		return &MismatchError{Expected: g.chainID, Actual: chainID}
+	}
+	return nil
+}
+
+func (g *guardedSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if err := g.check(chainID); err != nil {
+		return nil, err
+	}
+	// 其余类型的交易自带链 ID（绑定构造的交易留空，由签名时填入），非空时一并检查
+	if tx.Type() != types.LegacyTxType && tx.ChainId().Sign() != 0 {
+		if err := g.check(tx.ChainId()); err != nil {
+			return nil, err
+		}
+	}
+	return g.Signer.SignTx(ctx, tx, chainID)
+}
+
+func (g *guardedSigner) TransactOpts(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error) {
+	if err := g.check(chainID); err != nil {
+		return nil, err
+	}
+	opts, err := g.Signer.TransactOpts(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	// 绑定构造的交易也经过链 ID 检查
+	from := opts.From
+	opts.Signer = func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if address != from {
+			return nil, bind.ErrNotAuthorized
+		}
+		return g.SignTx(ctx, tx, chainID)
+	}
+	return opts, nil
+}
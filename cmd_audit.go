@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"sepolia-block/audit"
+	"sepolia-block/i18n"
+	"sepolia-block/indexer"
+	"sepolia-block/output"
+)
+
+// auditRange 一个区块区间的核对结果
+type auditRange struct {
+	From   uint64   `json:"from"`
+	To     uint64   `json:"to"`
+	Before *big.Int `json:"x_before"`
+	After  *big.Int `json:"x_after"`
+	Delta  *big.Int `json:"delta"`
+	Events int      `json:"events"`
+	Sum    *big.Int `json:"sum"`
+	OK     bool     `json:"ok"`
+}
+
+func newAuditRange(r audit.Range) auditRange {
+	return auditRange{
+		From:   r.From,
+		To:     r.To,
+		Before: r.Before,
+		After:  r.After,
+		Delta:  r.Delta(),
+		Events: r.Events,
+		Sum:    r.Sum,
+		OK:     r.OK(),
+	}
+}
+
+func (r auditRange) Columns() []string {
+	return []string{"from", "to", "x_before", "x_after", "delta", "events", "sum", "ok"}
+}
+
+func (r auditRange) Values() []string {
+	return []string{
+		strconv.FormatUint(r.From, 10),
+		strconv.FormatUint(r.To, 10),
+		r.Before.String(),
+		r.After.String(),
+		r.Delta.String(),
+		strconv.Itoa(r.Events),
+		r.Sum.String(),
+		strconv.FormatBool(r.OK),
+	}
+}
+
+func (r auditRange) Lines() []string {
+	if r.OK {
+		return []string{msgs.Sprintf(i18n.AuditRangeOK, r.From, r.To, r.Before, r.After, r.Events)}
+	}
+	return []string{msgs.Sprintf(i18n.AuditRangeMismatch, r.From, r.To, r.Delta, r.Events, r.Sum)}
+}
+
+// runCounterAudit 按区间核对 x 的变化与 Increment 事件 by 之和，有不一致的区间时返回错误
+func runCounterAudit(args []string) error {
+	var f counterFlags
+	fs := newCounterFlagSet("audit", &f)
+	from := fs.Uint64("from", 0, msgs.Sprintf(i18n.FlagAuditFrom))
+	block := fs.Int64("block", -1, msgs.Sprintf(i18n.FlagAuditBlock))
+	step := fs.Uint64("step", indexer.DefaultChunkSize, msgs.Sprintf(i18n.FlagAuditStep))
+	indexed := fs.Bool("indexed", false, msgs.Sprintf(i18n.FlagAuditIndexed))
+	db := fs.String("db", defaultIndexDB(), msgs.Sprintf(i18n.FlagIndexDB))
+	if err := f.parse(fs, args); err != nil {
+		return err
+	}
+	if *step == 0 {
+		return msgs.Errorf(i18n.ErrInvalidFlag, "step", *step)
+	}
+	client, c, err := f.bind()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	end := uint64(*block)
+	if *block < 0 {
+		if end, err = client.BlockNumber(ctx); err != nil {
+			return err
+		}
+	}
+	address := common.HexToAddress(f.address)
+
+	sum := audit.FilterSum(c)
+	if *indexed {
+		store, err := indexer.OpenStore(*db)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		sum = audit.StoreSum(store, client.ID(), address)
+	}
+
+	start := *from
+	if start == 0 {
+		if start, err = indexer.DeploymentBlock(ctx, client, address, end); err != nil {
+			return msgs.Errorf(i18n.ErrDeployBlock, err)
+		}
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressDeployBlock, start))
+	}
+	if start > end {
+		return msgs.Errorf(i18n.ErrInvalidRange, fmt.Sprintf("%d-%d", start, end))
+	}
+
+	ranges, err := audit.New(&c.CounterCaller, sum).Audit(ctx, start, end, *step, func(r audit.Range) {
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressAudit, r.From, r.To, end, r.Events))
+	})
+	if err != nil {
+		return err
+	}
+	records := make([]auditRange, len(ranges))
+	mismatched := 0
+	for i, r := range ranges {
+		records[i] = newAuditRange(r)
+		if !r.OK() {
+			mismatched++
+		}
+	}
+	if err := f.printList(output.List(records)); err != nil {
+		return err
+	}
+	if mismatched > 0 {
+		return msgs.Errorf(i18n.ErrAuditMismatch, mismatched, len(ranges), start, end)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/sync/errgroup"
+
+	"sepolia-block/chain"
+	"sepolia-block/i18n"
+	"sepolia-block/output"
+)
+
+type blockResult struct {
+	Number        uint64   `json:"number"`
+	Hash          string   `json:"hash"`
+	ParentHash    string   `json:"parent_hash"`
+	Time          uint64   `json:"timestamp"`
+	Miner         string   `json:"miner"`
+	GasUsed       uint64   `json:"gas_used"`
+	GasLimit      uint64   `json:"gas_limit"`
+	BaseFee       *big.Int `json:"base_fee,omitempty"`
+	BlobGasUsed   *uint64  `json:"blob_gas_used,omitempty"`
+	ExcessBlobGas *uint64  `json:"excess_blob_gas,omitempty"`
+	Withdrawals   *int     `json:"withdrawals,omitempty"`
+	Transactions  int      `json:"transactions"`
+
+	Txs []blockTx `json:"txs,omitempty"`
+}
+
+// blockTx 区块中的一笔交易，发送方由签名恢复
+type blockTx struct {
+	Hash  string   `json:"hash"`
+	Type  uint8    `json:"type"`
+	From  string   `json:"from"`
+	To    string   `json:"to,omitempty"` // 为空表示创建合约
+	Value *big.Int `json:"value"`
+	Nonce uint64   `json:"nonce"`
+	Gas   uint64   `json:"gas"`
+}
+
+// 区块名，对应 JSON-RPC 的 block tag
+var blockTags = map[string]rpc.BlockNumber{
+	"latest":    rpc.LatestBlockNumber,
+	"safe":      rpc.SafeBlockNumber,
+	"finalized": rpc.FinalizedBlockNumber,
+	"pending":   rpc.PendingBlockNumber,
+	"earliest":  rpc.EarliestBlockNumber,
+}
+
+func runBlock(args []string) error {
+	var g globalFlags
+	fs := newFlagSet("block", &g)
+	txs := fs.Bool("txs", false, msgs.Sprintf(i18n.FlagTxs))
+	concurrency := fs.Int("concurrency", 8, msgs.Sprintf(i18n.FlagConcurrency))
+	spec, err := g.parseArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if spec == "" {
+		spec = "latest"
+	}
+	if *concurrency < 1 {
+		return msgs.Errorf(i18n.ErrInvalidFlag, "concurrency", *concurrency)
+	}
+
+	client, err := g.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close() // 关闭
+
+	ctx := context.Background()
+	// 区块范围：from-to，并发查询
+	if from, to, ok, err := parseBlockRange(spec); err != nil {
+		return err
+	} else if ok {
+		blocks, err := fetchBlocks(ctx, client, from, to, *concurrency)
+		if err != nil {
+			return msgs.Errorf(i18n.ErrFetchBlock, err)
+		}
+		results := make([]blockResult, len(blocks))
+		for i, block := range blocks {
+			if results[i], err = describeBlock(client, block, *txs); err != nil {
+				return err
+			}
+		}
+		return g.printList(output.List(results))
+	}
+
+	block, err := fetchBlock(ctx, client, spec)
+	if err != nil {
+		return msgs.Errorf(i18n.ErrFetchBlock, err)
+	}
+	res, err := describeBlock(client, block, *txs)
+	if err != nil {
+		return err
+	}
+	return g.print(res)
+}
+
+// fetchBlock 按区块号、区块哈希或区块名查询
+func fetchBlock(ctx context.Context, client *chain.Client, spec string) (*types.Block, error) {
+	if tag, ok := blockTags[spec]; ok {
+		return client.BlockByNumber(ctx, big.NewInt(tag.Int64()))
+	}
+	if strings.HasPrefix(spec, "0x") && len(spec) == 66 {
+		return client.BlockByHash(ctx, common.HexToHash(spec))
+	}
+	number, err := strconv.ParseUint(spec, 10, 64)
+	if err != nil {
+		return nil, msgs.Errorf(i18n.ErrInvalidBlock, spec)
+	}
+	return client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+}
+
+// maxBlockRange 一次 block 命令最多查询的区块数，结果全部保存在内存中
+const maxBlockRange = 1000
+
+// parseBlockRange 解析 from-to 形式的区块范围，最多 maxBlockRange 个区块
+func parseBlockRange(spec string) (from, to uint64, ok bool, err error) {
+	lo, hi, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+	if from, err = strconv.ParseUint(lo, 10, 64); err != nil {
+		return 0, 0, false, msgs.Errorf(i18n.ErrInvalidRange, spec)
+	}
+	if to, err = strconv.ParseUint(hi, 10, 64); err != nil || to < from {
+		return 0, 0, false, msgs.Errorf(i18n.ErrInvalidRange, spec)
+	}
+	// 先比较差值，避免 to-from+1 溢出
+	if to-from >= maxBlockRange {
+		return 0, 0, false, msgs.Errorf(i18n.ErrRangeTooLarge, spec, maxBlockRange)
+	}
+	return from, to, true, nil
+}
+
+// fetchBlocks 并发查询 [from, to] 内的区块，结果按区块号排序
+func fetchBlocks(ctx context.Context, client *chain.Client, from, to uint64, concurrency int) ([]*types.Block, error) {
+	blocks := make([]*types.Block, to-from+1)
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(concurrency)
+	for i := range blocks {
+		group.Go(func() error {
+			block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(from+uint64(i)))
+			if err != nil {
+				return msgs.Errorf(i18n.ErrFetchBlockNumber, from+uint64(i), err)
+			}
+			blocks[i] = block
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func describeBlock(client *chain.Client, block *types.Block, withTxs bool) (blockResult, error) {
+	header := block.Header()
+	res := blockResult{
+		Number:        block.NumberU64(),
+		Hash:          block.Hash().Hex(),
+		ParentHash:    block.ParentHash().Hex(),
+		Time:          block.Time(),
+		Miner:         block.Coinbase().Hex(),
+		GasUsed:       block.GasUsed(),
+		GasLimit:      block.GasLimit(),
+		BaseFee:       block.BaseFee(),
+		BlobGasUsed:   header.BlobGasUsed,
+		ExcessBlobGas: header.ExcessBlobGas,
+		Transactions:  len(block.Transactions()),
+	}
+	// 上海升级之前的区块没有 withdrawals 字段
+	if header.WithdrawalsHash != nil {
+		n := len(block.Withdrawals())
+		res.Withdrawals = &n
+	}
+	if !withTxs {
+		return res, nil
+	}
+	signer := types.LatestSignerForChainID(client.ID())
+	for _, tx := range block.Transactions() {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return res, msgs.Errorf(i18n.ErrRecoverSender, tx.Hash().Hex(), err)
+		}
+		info := blockTx{
+			Hash:  tx.Hash().Hex(),
+			Type:  tx.Type(),
+			From:  from.Hex(),
+			Value: tx.Value(),
+			Nonce: tx.Nonce(),
+			Gas:   tx.Gas(),
+		}
+		if to := tx.To(); to != nil {
+			info.To = to.Hex()
+		}
+		res.Txs = append(res.Txs, info)
+	}
+	return res, nil
+}
+
+// Columns CSV 只包含区块头字段，-txs 的交易列表只在 text/JSON 中输出
+func (r blockResult) Columns() []string {
+	return []string{
+		"number", "hash", "parent_hash", "timestamp", "miner", "gas_used", "gas_limit",
+		"base_fee", "blob_gas_used", "excess_blob_gas", "withdrawals", "transactions",
+	}
+}
+
+func (r blockResult) Values() []string {
+	return []string{
+		strconv.FormatUint(r.Number, 10),
+		r.Hash,
+		r.ParentHash,
+		strconv.FormatUint(r.Time, 10),
+		r.Miner,
+		strconv.FormatUint(r.GasUsed, 10),
+		strconv.FormatUint(r.GasLimit, 10),
+		bigString(r.BaseFee),
+		optional(r.BlobGasUsed),
+		optional(r.ExcessBlobGas),
+		optional(r.Withdrawals),
+		strconv.Itoa(r.Transactions),
+	}
+}
+
+func (r blockResult) Lines() []string {
+	lines := []string{
+		msgs.Sprintf(i18n.BlockNumber, r.Number),
+		msgs.Sprintf(i18n.BlockHash, r.Hash),
+		msgs.Sprintf(i18n.BlockParentHash, r.ParentHash),
+		msgs.Sprintf(i18n.BlockTime, r.Time),
+		msgs.Sprintf(i18n.BlockMiner, r.Miner),
+		msgs.Sprintf(i18n.BlockGas, r.GasUsed, r.GasLimit),
+	}
+	if r.BaseFee != nil {
+		lines = append(lines, msgs.Sprintf(i18n.BlockBaseFee, r.BaseFee))
+	}
+	if r.BlobGasUsed != nil && r.ExcessBlobGas != nil {
+		lines = append(lines, msgs.Sprintf(i18n.BlockBlobGas, *r.BlobGasUsed, *r.ExcessBlobGas))
+	}
+	if r.Withdrawals != nil {
+		lines = append(lines, msgs.Sprintf(i18n.BlockWithdrawals, *r.Withdrawals))
+	}
+	lines = append(lines, msgs.Sprintf(i18n.BlockTxCount, r.Transactions))
+	for _, tx := range r.Txs {
+		to := tx.To
+		if to == "" {
+			to = msgs.Sprintf(i18n.BlockTxCreate)
+		}
+		lines = append(lines, msgs.Sprintf(i18n.BlockTx, tx.Hash, tx.From, to, tx.Value))
+	}
+	return lines
+}
+
+// optional 把可选字段格式化为字符串，缺失时为空
+func optional[T any](v *T) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(*v)
+}
+
+// bigString 格式化可能为 nil 的大整数，缺失时为空
+func bigString(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"sepolia-block/i18n"
+)
+
+func TestParseBlockRange(t *testing.T) {
+	msgs = i18n.NewPrinter(i18n.EN)
+	tests := []struct {
+		spec     string
+		from, to uint64
+		ok       bool
+		err      string
+	}{
+		{"latest", 0, 0, false, ""},
+		{"5-5", 5, 5, true, ""},
+		{"100-1099", 100, 1099, true, ""},
+		{"100-1100", 0, 0, false, "too large"},
+		{"0-4000000000", 0, 0, false, "too large"},
+		{"0-18446744073709551615", 0, 0, false, "too large"},
+		{"10-9", 0, 0, false, "invalid block range"},
+		{"a-9", 0, 0, false, "invalid block range"},
+	}
+	for _, tt := range tests {
+		from, to, ok, err := parseBlockRange(tt.spec)
+		switch {
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("parseBlockRange(%q) error %v, want %q", tt.spec, err, tt.err)
+		case tt.err == "" && err != nil:
+			t.Errorf("parseBlockRange(%q): %v", tt.spec, err)
+		case from != tt.from || to != tt.to || ok != tt.ok:
+			t.Errorf("parseBlockRange(%q) = %d, %d, %v, want %d, %d, %v", tt.spec, from, to, ok, tt.from, tt.to, tt.ok)
+		}
+	}
+}
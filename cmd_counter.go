@@ -0,0 +1,498 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"sepolia-block/chain"
+	"sepolia-block/config"
+	"sepolia-block/counter"
+	"sepolia-block/deploy"
+	"sepolia-block/i18n"
+	"sepolia-block/output"
+	"sepolia-block/verify"
+)
+
+var counterCommands = map[string]command{
+	"deploy":  {i18n.CmdCounterDeploy, runCounterDeploy},
+	"inc":     {i18n.CmdCounterInc, runCounterInc},
+	"inc-by":  {i18n.CmdCounterIncBy, runCounterIncBy},
+	"get":     {i18n.CmdCounterGet, runCounterGet},
+	"events":  {i18n.CmdCounterEvents, runCounterEvents},
+	"index":   {i18n.CmdCounterIndex, runCounterIndex},
+	"follow":  {i18n.CmdCounterFollow, runCounterFollow},
+	"audit":   {i18n.CmdCounterAudit, runCounterAudit},
+	"verify":  {i18n.CmdCounterVerify, runCounterVerify},
+	"migrate": {i18n.CmdCounterMigrate, runCounterMigrate},
+}
+
+func runCounter(args []string) error {
+	return dispatch("sepolia-block counter", counterCommands, args)
+}
+
+type counterFlags struct {
+	globalFlags
+	address string
+	count   int
+}
+
+func newCounterFlagSet(name string, f *counterFlags) *flag.FlagSet {
+	fs := newFlagSet("counter "+name, &f.globalFlags)
+	fs.StringVar(&f.address, "address", config.DefaultCounter, msgs.Sprintf(i18n.FlagAddress))
+	return fs
+}
+
+// parse 解析参数，并把 -address 从地址簿解析为十六进制地址
+func (f *counterFlags) parse(fs *flag.FlagSet, args []string) error {
+	if err := f.globalFlags.parse(fs, args); err != nil {
+		return err
+	}
+	addr, err := f.profile.Counter(f.address)
+	if err != nil {
+		return err
+	}
+	f.address = addr.Hex()
+	return nil
+}
+
+// bind 连接节点并绑定 Counter 合约
+func (f *counterFlags) bind() (*chain.Client, *counter.Counter, error) {
+	client, err := f.dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	backend, err := f.backend(client)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	c, err := counter.NewCounter(common.HexToAddress(f.address), backend)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	return client, c, nil
+}
+
+type deployResult struct {
+	Address  string `json:"address"`
+	Hash     string `json:"hash"`
+	Salt     string `json:"salt,omitempty"`     // CREATE2 部署时的 salt
+	Existing bool   `json:"existing,omitempty"` // CREATE2 预测地址上已有 Counter，未发送交易
+
+	Receipt *receiptInfo `json:"receipt,omitempty"`
+}
+
+func (r deployResult) Columns() []string {
+	return append([]string{"address", "hash", "salt", "existing"}, receiptColumns...)
+}
+
+func (r deployResult) Values() []string {
+	return append([]string{r.Address, r.Hash, r.Salt, strconv.FormatBool(r.Existing)}, r.Receipt.values()...)
+}
+
+func (r deployResult) Lines() []string {
+	if r.Existing {
+		return []string{msgs.Sprintf(i18n.CounterCreate2Existing, r.Address)}
+	}
+	return append([]string{
+		msgs.Sprintf(i18n.CounterDeployed, r.Address),
+		msgs.Sprintf(i18n.CounterDeployTx, r.Hash),
+	}, r.Receipt.lines()...)
+}
+
+func runCounterDeploy(args []string) error {
+	var g globalFlags
+	fs := newFlagSet("counter deploy", &g)
+	name := fs.String("save", "", msgs.Sprintf(i18n.FlagSave))
+	create2 := fs.Bool("create2", false, msgs.Sprintf(i18n.FlagCreate2))
+	salt := fs.String("salt", "", msgs.Sprintf(i18n.FlagSalt))
+	factory := fs.String("factory", deploy.DeterministicProxy.Hex(), msgs.Sprintf(i18n.FlagFactory))
+	manifest := fs.String("manifest", deploy.DefaultManifest, msgs.Sprintf(i18n.FlagManifest))
+	g.tx.register(fs)
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if *create2 {
+		if !common.IsHexAddress(*factory) {
+			return msgs.Errorf(i18n.ErrInvalidFlag, "factory", *factory)
+		}
+		c, err := deploy.NewCreate2(counter.CounterMetaData.Bin, common.Hash{})
+		if err != nil {
+			return err
+		}
+		if c.Salt, err = deploy.ParseSalt(*salt); err != nil {
+			return msgs.Errorf(i18n.ErrInvalidFlag, "salt", err)
+		}
+		c.Factory = common.HexToAddress(*factory)
+		return g.deployCreate2(c, cmp.Or(*name, defaultCreate2Name), *manifest)
+	}
+	client, err := g.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	auth, err := g.transactOpts(ctx, client)
+	if err != nil {
+		return err
+	}
+	backend, err := g.backend(client)
+	if err != nil {
+		return err
+	}
+	nonces, err := g.nonceManager(client)
+	if err != nil {
+		return err
+	}
+	var address common.Address
+	tx, err := nonces.Send(ctx, client.ID(), auth.From, func(nonce uint64) (*types.Transaction, error) {
+		opts := *auth
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		var tx *types.Transaction
+		var err error
+		address, tx, _, err = counter.DeployCounter(&opts, backend)
+		return tx, err
+	})
+	if tx == nil {
+		return err
+	}
+	if err != nil {
+		// 交易已发出，只是在途记录写入失败
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.Warning, err))
+	}
+	res := deployResult{Address: address.Hex(), Hash: tx.Hash().Hex()}
+	res.Receipt, err = g.wait(client, tx.Hash())
+	// 交易未失败才写入地址簿和清单
+	if err == nil && *name != "" {
+		g.cfg.SetCounter(g.profile.Name, *name, address)
+		if err := g.cfg.Save(); err != nil {
+			return err
+		}
+	}
+	if err == nil {
+		d := deploy.Deployment{
+			Name:         *name,
+			Address:      address,
+			TxHash:       tx.Hash(),
+			Deployer:     auth.From,
+			BytecodeHash: deploy.BytecodeHash(common.FromHex(counter.CounterMetaData.Bin)),
+		}
+		if res.Receipt != nil {
+			d.Block = res.Receipt.Block
+		}
+		if err := g.recordDeployment(*manifest, d); err != nil {
+			return err
+		}
+	}
+	return g.printTx(res, err)
+}
+
+// defaultCreate2Name 未指定 -save 时 CREATE2 部署写入地址簿的名字
+const defaultCreate2Name = "create2"
+
+// deployCreate2 通过 CREATE2 工厂部署 Counter，预测地址上已有 Counter 时跳过，结果写入地址簿的 name 下，
+// 新部署同时记入清单 manifest
+func (g *globalFlags) deployCreate2(c deploy.Create2, name, manifest string) error {
+	client, err := g.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressCreate2, c.Address().Hex(), c.Factory.Hex(), c.Salt.Hex()))
+	auth, err := g.transactOpts(ctx, client)
+	if err != nil {
+		return err
+	}
+	backend, err := g.backend(client)
+	if err != nil {
+		return err
+	}
+	nonces, err := g.nonceManager(client)
+	if err != nil {
+		return err
+	}
+	address := c.Address()
+	// 预测地址上已有代码时 Deploy2 不发送交易，Send 返回 nil, nil 并归还 nonce
+	tx, err := nonces.Send(ctx, client.ID(), auth.From, func(nonce uint64) (*types.Transaction, error) {
+		opts := *auth
+		opts.Nonce = new(big.Int).SetUint64(nonce)
+		_, tx, err := deploy.Deploy2(ctx, &opts, backend, c)
+		return tx, err
+	})
+	if tx == nil && err != nil {
+		return err
+	}
+	if err != nil {
+		// 交易已发出，只是在途记录写入失败
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.Warning, err))
+	}
+	res := deployResult{Address: address.Hex(), Salt: c.Salt.Hex(), Existing: tx == nil}
+	if tx != nil {
+		res.Hash = tx.Hash().Hex()
+		res.Receipt, err = g.wait(client, tx.Hash())
+	}
+	if err == nil && !g.tx.noWait {
+		// 预测地址上的代码必须是 Counter：已有代码时可能是同地址上的其他合约，新部署时确认工厂确实创建了合约
+		vr, verr := verify.Verify(ctx, client, address, counter.CounterMetaData.Bin, nil)
+		if verr != nil {
+			return verr
+		}
+		if !vr.Status.OK() {
+			err = msgs.Errorf(i18n.ErrNotCounter, address.Hex())
+		}
+	}
+	if err == nil {
+		g.cfg.SetCounter(g.profile.Name, name, address)
+		if err := g.cfg.Save(); err != nil {
+			return err
+		}
+	}
+	if err == nil && tx != nil {
+		d := deploy.Deployment{
+			Name:         name,
+			Address:      address,
+			TxHash:       tx.Hash(),
+			Deployer:     auth.From,
+			BytecodeHash: deploy.BytecodeHash(c.InitCode),
+			Factory:      &c.Factory,
+			Salt:         &c.Salt,
+		}
+		if res.Receipt != nil {
+			d.Block = res.Receipt.Block
+		}
+		if err := g.recordDeployment(manifest, d); err != nil {
+			return err
+		}
+	}
+	return g.printTx(res, err)
+}
+
+func runCounterInc(args []string) error {
+	var f counterFlags
+	fs := newCounterFlagSet("inc", &f)
+	f.registerSend(fs)
+	if err := f.parse(fs, args); err != nil {
+		return err
+	}
+	// 调用 inc() 修改状态
+	return f.send("Increment", func(c *counter.Counter, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.Inc(opts)
+	})
+}
+
+func runCounterIncBy(args []string) error {
+	var f counterFlags
+	fs := newCounterFlagSet("inc-by", &f)
+	f.registerSend(fs)
+	by := fs.String("by", "1", msgs.Sprintf(i18n.FlagBy))
+	if err := f.parse(fs, args); err != nil {
+		return err
+	}
+	amount, ok := new(big.Int).SetString(*by, 10)
+	if !ok {
+		return msgs.Errorf(i18n.ErrInvalidIncrement, *by)
+	}
+	return f.send("IncrementBy", func(c *counter.Counter, opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.IncBy(opts, amount)
+	})
+}
+
+// registerSend 注册发送交易相关的参数
+func (f *counterFlags) registerSend(fs *flag.FlagSet) {
+	f.tx.register(fs)
+	fs.IntVar(&f.count, "count", 1, msgs.Sprintf(i18n.FlagCount))
+}
+
+// send 并发发送 -count 笔交易，nonce 由 NonceManager 统一分配，然后逐笔等待确认
+func (f *counterFlags) send(label string, transact func(*counter.Counter, *bind.TransactOpts) (*types.Transaction, error)) error {
+	if f.count < 1 {
+		return msgs.Errorf(i18n.ErrInvalidFlag, "count", f.count)
+	}
+	client, c, err := f.bind()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	auth, err := f.transactOpts(ctx, client)
+	if err != nil {
+		return err
+	}
+	nonces, err := f.nonceManager(client)
+	if err != nil {
+		return err
+	}
+	chainID := client.ID()
+
+	txs := make([]*types.Transaction, f.count)
+	errs := make([]error, f.count)
+	var wg sync.WaitGroup
+	for i := range txs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			txs[i], errs[i] = nonces.Send(ctx, chainID, auth.From, func(nonce uint64) (*types.Transaction, error) {
+				opts := *auth
+				opts.Nonce = new(big.Int).SetUint64(nonce)
+				return transact(c, &opts)
+			})
+		}()
+	}
+	wg.Wait()
+
+	var (
+		results  []txResult
+		firstErr error
+	)
+	for i, tx := range txs {
+		if tx == nil {
+			firstErr = cmp.Or(firstErr, errs[i])
+			continue
+		}
+		if errs[i] != nil {
+			// 交易已发出，只是在途记录写入失败
+			fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.Warning, errs[i]))
+		}
+		res := txResult{
+			Hash:    tx.Hash().Hex(),
+			From:    auth.From.Hex(),
+			To:      f.address,
+			summary: msgs.Sprintf(i18n.CounterTxSent, label, tx.Hash().Hex()),
+		}
+		res.Receipt, err = f.wait(client, tx.Hash())
+		firstErr = cmp.Or(firstErr, err)
+		results = append(results, res)
+	}
+	if len(results) == 1 && f.count == 1 {
+		return f.printTx(results[0], firstErr)
+	}
+	if err := f.printList(output.List(results)); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+type counterValue struct {
+	Address string   `json:"address"`
+	Value   *big.Int `json:"value"`
+}
+
+func (v counterValue) Columns() []string { return []string{"address", "value"} }
+
+func (v counterValue) Values() []string { return []string{v.Address, v.Value.String()} }
+
+func (v counterValue) Lines() []string {
+	return []string{msgs.Sprintf(i18n.CounterValue, v.Value)}
+}
+
+func runCounterGet(args []string) error {
+	var f counterFlags
+	fs := newCounterFlagSet("get", &f)
+	pending := fs.Bool("pending", false, msgs.Sprintf(i18n.FlagPending))
+	if err := f.parse(fs, args); err != nil {
+		return err
+	}
+	client, c, err := f.bind()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// 调用自动生成 get() 读取当前计数
+	num, err := c.X(&bind.CallOpts{Pending: *pending, Context: context.Background()})
+	if err != nil {
+		return err
+	}
+	res := counterValue{Address: f.address, Value: num}
+	return f.print(res)
+}
+
+type incrementEvent struct {
+	Block    uint64   `json:"block"`
+	TxHash   string   `json:"tx_hash"`
+	LogIndex uint     `json:"log_index"`
+	By       *big.Int `json:"by"`
+}
+
+func (e incrementEvent) Columns() []string {
+	return []string{"block", "tx_hash", "log_index", "by"}
+}
+
+func (e incrementEvent) Values() []string {
+	return []string{
+		strconv.FormatUint(e.Block, 10),
+		e.TxHash,
+		strconv.FormatUint(uint64(e.LogIndex), 10),
+		e.By.String(),
+	}
+}
+
+func (e incrementEvent) Lines() []string {
+	return []string{msgs.Sprintf(i18n.CounterIncrement, e.Block, e.TxHash, e.By)}
+}
+
+func runCounterEvents(args []string) error {
+	var f counterFlags
+	fs := newCounterFlagSet("events", &f)
+	from := fs.Uint64("from", 0, msgs.Sprintf(i18n.FlagFromBlock))
+	to := fs.Int64("to", -1, msgs.Sprintf(i18n.FlagToBlock))
+	indexed := fs.Bool("indexed", false, msgs.Sprintf(i18n.FlagIndexed))
+	db := fs.String("db", defaultIndexDB(), msgs.Sprintf(i18n.FlagIndexDB))
+	if err := f.parse(fs, args); err != nil {
+		return err
+	}
+	if *indexed {
+		end := uint64(math.MaxUint64)
+		if *to >= 0 {
+			end = uint64(*to)
+		}
+		return f.printIndexed(*db, *from, end)
+	}
+	client, c, err := f.bind()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	opts := &bind.FilterOpts{Start: *from, Context: context.Background()}
+	if *to >= 0 {
+		end := uint64(*to)
+		opts.End = &end
+	}
+	it, err := c.FilterIncrement(opts)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	var events []incrementEvent
+	for it.Next() {
+		ev := incrementEvent{
+			Block:    it.Event.Raw.BlockNumber,
+			TxHash:   it.Event.Raw.TxHash.Hex(),
+			LogIndex: it.Event.Raw.Index,
+			By:       it.Event.By,
+		}
+		events = append(events, ev)
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return f.printList(output.List(events))
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"sepolia-block/chain"
+	"sepolia-block/counter"
+	"sepolia-block/i18n"
+	"sepolia-block/indexer"
+	"sepolia-block/output"
+)
+
+// printIndexed 从本地索引读取事件，不连接节点
+func (f *counterFlags) printIndexed(path string, from, to uint64) error {
+	store, err := indexer.OpenStore(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	stored, err := store.Events(new(big.Int).SetUint64(f.chainID), common.HexToAddress(f.address), from, to)
+	if err != nil {
+		return err
+	}
+	events := make([]incrementEvent, len(stored))
+	for i, ev := range stored {
+		events[i] = incrementEvent{
+			Block:    ev.Block,
+			TxHash:   ev.TxHash.Hex(),
+			LogIndex: ev.LogIndex,
+			By:       ev.By,
+		}
+	}
+	return f.printList(output.List(events))
+}
+
+// defaultIndexDB 用户缓存目录下的事件索引
+func defaultIndexDB() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sepolia-block", "index.db")
+}
+
+type indexResult struct {
+	Address string `json:"address"`
+	From    uint64 `json:"from"`
+	To      uint64 `json:"to"`
+	Events  int    `json:"events"`
+}
+
+func (r indexResult) Columns() []string {
+	return []string{"address", "from", "to", "events"}
+}
+
+func (r indexResult) Values() []string {
+	return []string{
+		r.Address,
+		strconv.FormatUint(r.From, 10),
+		strconv.FormatUint(r.To, 10),
+		strconv.Itoa(r.Events),
+	}
+}
+
+func (r indexResult) Lines() []string {
+	if r.From > r.To {
+		return []string{msgs.Sprintf(i18n.IndexUpToDate, r.To)}
+	}
+	return []string{msgs.Sprintf(i18n.IndexResult, r.From, r.To, r.Events)}
+}
+
+// runCounterIndex 从部署区块（或上次索引到的区块）开始分块回填 Increment 事件
+func runCounterIndex(args []string) error {
+	var f counterFlags
+	fs := newCounterFlagSet("index", &f)
+	from := fs.Uint64("from", 0, msgs.Sprintf(i18n.FlagDeployBlock))
+	to := fs.Int64("to", -1, msgs.Sprintf(i18n.FlagToBlock))
+	chunk := fs.Uint64("chunk", indexer.DefaultChunkSize, msgs.Sprintf(i18n.FlagChunk))
+	db := fs.String("db", defaultIndexDB(), msgs.Sprintf(i18n.FlagIndexDB))
+	if err := f.parse(fs, args); err != nil {
+		return err
+	}
+	if *chunk == 0 {
+		return msgs.Errorf(i18n.ErrInvalidFlag, "chunk", *chunk)
+	}
+	client, c, err := f.bind()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	store, err := indexer.OpenStore(*db)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	end := uint64(*to)
+	if *to < 0 {
+		if end, err = client.BlockNumber(ctx); err != nil {
+			return err
+		}
+	}
+	address := common.HexToAddress(f.address)
+	start := *from
+	if _, ok, err := store.Last(client.ID(), address); err != nil {
+		return err
+	} else if !ok && start == 0 {
+		if start, err = indexer.DeploymentBlock(ctx, client, address, end); err != nil {
+			return msgs.Errorf(i18n.ErrDeployBlock, err)
+		}
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressDeployBlock, start))
+	}
+
+	ix := f.newIndexer(client, c, store, *chunk)
+	ix.OnChunk = func(c indexer.Chunk) {
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressIndexChunk, c.From, c.To, c.Events))
+	}
+	if _, err := ix.Reconcile(ctx); err != nil {
+		return err
+	}
+	first, added, err := ix.Backfill(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	return f.print(indexResult{Address: f.address, From: first, To: end, Events: added})
+}
+
+// newIndexer 创建 Counter 合约的 Indexer，分块缩小时打印提示
+func (f *counterFlags) newIndexer(client *chain.Client, c *counter.Counter, store *indexer.Store, chunk uint64) *indexer.Indexer {
+	ix := indexer.New(c, client, store, client.ID(), common.HexToAddress(f.address))
+	ix.ChunkSize = chunk
+	ix.OnShrink = func(size uint64, err error) {
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressIndexShrink, size, err))
+	}
+	return ix
+}
+
+// followEvent 跟随过程中写入或因重组撤销的事件
+type followEvent struct {
+	incrementEvent
+	Removed bool `json:"removed"`
+}
+
+func (e followEvent) Columns() []string {
+	return append(e.incrementEvent.Columns(), "removed")
+}
+
+func (e followEvent) Values() []string {
+	return append(e.incrementEvent.Values(), strconv.FormatBool(e.Removed))
+}
+
+func (e followEvent) Lines() []string {
+	if e.Removed {
+		return []string{msgs.Sprintf(i18n.CounterIncrementRemoved, e.Block, e.TxHash, e.By)}
+	}
+	return e.incrementEvent.Lines()
+}
+
+// runCounterFollow 持续跟随 Increment 事件并写入本地索引，直到收到中断信号
+func runCounterFollow(args []string) error {
+	var f counterFlags
+	fs := newCounterFlagSet("follow", &f)
+	from := fs.Uint64("from", 0, msgs.Sprintf(i18n.FlagFollowFrom))
+	chunk := fs.Uint64("chunk", indexer.DefaultChunkSize, msgs.Sprintf(i18n.FlagChunk))
+	db := fs.String("db", defaultIndexDB(), msgs.Sprintf(i18n.FlagIndexDB))
+	poll := fs.Bool("poll", false, msgs.Sprintf(i18n.FlagPoll))
+	interval := fs.Duration("poll-interval", 12*time.Second, msgs.Sprintf(i18n.FlagPollInterval))
+	if err := f.parse(fs, args); err != nil {
+		return err
+	}
+	if *chunk == 0 {
+		return msgs.Errorf(i18n.ErrInvalidFlag, "chunk", *chunk)
+	}
+	if *interval <= 0 {
+		return msgs.Errorf(i18n.ErrInvalidFlag, "poll-interval", *interval)
+	}
+	client, c, err := f.bind()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	store, err := indexer.OpenStore(*db)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// 事件库为空且未指定 -from 时只跟随新事件，历史事件由 counter index 回填
+	start := *from
+	if _, ok, err := store.Last(client.ID(), common.HexToAddress(f.address)); err != nil {
+		return err
+	} else if !ok && start == 0 {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		start = head + 1
+	}
+
+	w := f.writer()
+	var printErr error
+	fl := indexer.NewFollower(f.newIndexer(client, c, store, *chunk), c)
+	fl.Poll = *poll
+	fl.PollInterval = *interval
+	fl.OnEvent = func(ev indexer.Event, removed bool) {
+		rec := followEvent{
+			incrementEvent: incrementEvent{
+				Block:    ev.Block,
+				TxHash:   ev.TxHash.Hex(),
+				LogIndex: ev.LogIndex,
+				By:       ev.By,
+			},
+			Removed: removed,
+		}
+		if err := w.Stream(rec); err != nil && printErr == nil {
+			printErr = err
+			stop()
+		}
+	}
+	fl.OnConnect = func(subscribed bool) {
+		if subscribed {
+			fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressSubscribed))
+		} else {
+			fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressPolling, fl.PollInterval))
+		}
+	}
+	fl.OnError = func(err error, retry time.Duration) {
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressReconnect, err, retry))
+	}
+	err = fl.Run(ctx, start)
+	if printErr != nil {
+		return printErr
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
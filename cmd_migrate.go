@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"sepolia-block/deploy"
+	"sepolia-block/i18n"
+	"sepolia-block/output"
+)
+
+// migrationResult 一步迁移的执行结果
+type migrationResult struct {
+	ID      string `json:"id"`
+	Action  string `json:"action"`
+	Status  string `json:"status"`
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address"`
+	TxHash  string `json:"tx_hash,omitempty"`
+	Block   uint64 `json:"block,omitempty"`
+}
+
+func newMigrationResult(r deploy.Result) migrationResult {
+	res := migrationResult{
+		ID:      r.ID,
+		Action:  r.Action,
+		Status:  r.Status,
+		Name:    r.Name,
+		Address: r.Address.Hex(),
+		Block:   r.Block,
+	}
+	if r.TxHash != (common.Hash{}) {
+		res.TxHash = r.TxHash.Hex()
+	}
+	return res
+}
+
+func (r migrationResult) Columns() []string {
+	return []string{"id", "action", "status", "name", "address", "tx_hash", "block"}
+}
+
+func (r migrationResult) Values() []string {
+	return []string{r.ID, r.Action, r.Status, r.Name, r.Address, r.TxHash, strconv.FormatUint(r.Block, 10)}
+}
+
+func (r migrationResult) Lines() []string {
+	if r.Status == deploy.StatusSkipped {
+		return []string{msgs.Sprintf(i18n.MigrationSkipped, r.ID, r.Action)}
+	}
+	lines := []string{msgs.Sprintf(i18n.MigrationApplied, r.ID, r.Action, r.Address)}
+	if r.TxHash != "" {
+		lines = append(lines, msgs.Sprintf(i18n.MigrationTx, r.TxHash, r.Block))
+	}
+	return lines
+}
+
+// runCounterMigrate 按顺序执行迁移脚本，已执行的步骤按清单跳过，部署结果同时写入地址簿
+func runCounterMigrate(args []string) error {
+	var g globalFlags
+	fs := newFlagSet("counter migrate", &g)
+	file := fs.String("file", deploy.DefaultMigrations, msgs.Sprintf(i18n.FlagMigrations))
+	manifestPath := fs.String("manifest", deploy.DefaultManifest, msgs.Sprintf(i18n.FlagManifest))
+	g.tx.register(fs)
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if g.tx.noWait {
+		return msgs.Errorf(i18n.ErrMigrateNoWait)
+	}
+	if *manifestPath == "" {
+		return msgs.Errorf(i18n.ErrInvalidFlag, "manifest", `""`)
+	}
+	migrations, err := deploy.LoadMigrations(*file)
+	if err != nil {
+		return err
+	}
+	manifest, err := deploy.LoadManifest(*manifestPath)
+	if err != nil {
+		return err
+	}
+	client, err := g.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	auth, err := g.transactOpts(ctx, client)
+	if err != nil {
+		return err
+	}
+	backend, err := g.backend(client)
+	if err != nil {
+		return err
+	}
+	runner := &deploy.Runner{
+		Manifest: manifest,
+		Network:  g.profile.Name,
+		ChainID:  g.chainID,
+		Backend:  backend,
+		Opts:     auth,
+		Waiter:   g.tracker(client),
+		Chain:    client,
+		Timeout:  g.tx.timeout,
+		OnStep: func(m deploy.Migration) {
+			fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressMigration, m.ID, m.Action()))
+		},
+	}
+	results, runErr := runner.Run(ctx, migrations)
+
+	// 部署结果写入地址簿，之后的 counter 子命令可以直接用名字引用
+	changed := false
+	for _, r := range results {
+		if r.Action != deploy.ActionDeploy {
+			continue
+		}
+		if addr, err := g.profile.Counter(r.Name); err != nil || addr != r.Address {
+			g.cfg.SetCounter(g.profile.Name, r.Name, r.Address)
+			changed = true
+		}
+	}
+	if changed {
+		if err := g.cfg.Save(); err != nil {
+			return err
+		}
+	}
+
+	recs := make([]migrationResult, len(results))
+	for i, r := range results {
+		recs[i] = newMigrationResult(r)
+	}
+	if err := g.printList(output.List(recs)); err != nil {
+		return err
+	}
+	return runErr
+}
+
+// recordDeployment 把一次部署追加到清单，path 为空时不记录
+func (g *globalFlags) recordDeployment(path string, d deploy.Deployment) error {
+	if path == "" {
+		return nil
+	}
+	m, err := deploy.LoadManifest(path)
+	if err != nil {
+		return err
+	}
+	d.Network, d.ChainID = g.profile.Name, g.chainID
+	if err := m.Check(d.Network, d.ChainID); err != nil {
+		return err
+	}
+	m.AddDeployment(d)
+	return m.Save()
+}
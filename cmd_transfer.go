@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"sepolia-block/i18n"
+)
+
+type txResult struct {
+	Hash string `json:"hash"`
+	From string `json:"from"`
+	To   string `json:"to,omitempty"`
+
+	Receipt *receiptInfo `json:"receipt,omitempty"`
+
+	summary string // text 格式的第一行
+}
+
+func (r txResult) Columns() []string {
+	return append([]string{"hash", "from", "to"}, receiptColumns...)
+}
+
+func (r txResult) Values() []string {
+	return append([]string{r.Hash, r.From, r.To}, r.Receipt.values()...)
+}
+
+func (r txResult) Lines() []string {
+	return append([]string{r.summary}, r.Receipt.lines()...)
+}
+
+func runTransfer(args []string) error {
+	var g globalFlags
+	fs := newFlagSet("transfer", &g)
+	g.tx.register(fs)
+	to := fs.String("to", "", msgs.Sprintf(i18n.FlagRecipient))
+	value := fs.String("value", "100000000000000", msgs.Sprintf(i18n.FlagValue))
+	if err := g.parse(fs, args); err != nil {
+		return err
+	}
+	if !common.IsHexAddress(*to) {
+		return msgs.Errorf(i18n.ErrInvalidRecipient, *to)
+	}
+	amount, ok := new(big.Int).SetString(*value, 10)
+	if !ok {
+		return msgs.Errorf(i18n.ErrInvalidValue, *value)
+	}
+
+	client, err := g.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	s, err := g.signer(ctx, client)
+	if err != nil {
+		return err
+	}
+	fromAddress := s.Address()
+	fees, err := g.fees(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	// 按实际调用估算 gas，收款方是合约时 21000 不够
+	toAddress := common.HexToAddress(*to)
+	gasLimit := g.tx.gasLimit
+	if gasLimit == 0 {
+		msg := ethereum.CallMsg{
+			From:      fromAddress,
+			To:        &toAddress,
+			Value:     amount,
+			GasPrice:  fees.GasPrice,
+			GasFeeCap: fees.GasFeeCap,
+			GasTipCap: fees.GasTipCap,
+		}
+		if gasLimit, err = g.gasEstimator(client).Estimate(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	nonces, err := g.nonceManager(client)
+	if err != nil {
+		return err
+	}
+	chainID := client.ID()
+	signedTx, err := nonces.Send(ctx, chainID, fromAddress, func(nonce uint64) (*types.Transaction, error) {
+		// 构造交易，London 之后默认 DynamicFeeTx
+		tx := fees.NewTx(chainID, nonce, &toAddress, amount, gasLimit, nil)
+		// 签名交易
+		signedTx, err := s.SignTx(ctx, tx, chainID)
+		if err != nil {
+			return nil, err
+		}
+		// 发送交易
+		return signedTx, client.SendTransaction(ctx, signedTx)
+	})
+	if signedTx == nil {
+		return err
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.Warning, err))
+	}
+
+	res := txResult{
+		Hash:    signedTx.Hash().Hex(),
+		From:    fromAddress.Hex(),
+		To:      toAddress.Hex(),
+		summary: msgs.Sprintf(i18n.TransferSent, signedTx.Hash().Hex()),
+	}
+	res.Receipt, err = g.wait(client, signedTx.Hash())
+	return g.printTx(res, err)
+}
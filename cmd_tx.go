@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"sepolia-block/i18n"
+	"sepolia-block/txmgr"
+)
+
+var txCommands = map[string]command{
+	"speedup": {i18n.CmdTxSpeedup, runTxSpeedup},
+	"cancel":  {i18n.CmdTxCancel, runTxCancel},
+}
+
+func runTx(args []string) error {
+	return dispatch("sepolia-block tx", txCommands, args)
+}
+
+func runTxSpeedup(args []string) error {
+	return replaceTx("speedup", args, false)
+}
+
+func runTxCancel(args []string) error {
+	return replaceTx("cancel", args, true)
+}
+
+type replaceResult struct {
+	Original string `json:"original"`
+	Hash     string `json:"hash"`
+	Nonce    uint64 `json:"nonce"`
+	Mined    string `json:"mined,omitempty"` // 最终上链的交易
+
+	Receipt *receiptInfo `json:"receipt,omitempty"`
+
+	fees string
+}
+
+func (r replaceResult) Columns() []string {
+	return append([]string{"original", "hash", "nonce", "mined"}, receiptColumns...)
+}
+
+func (r replaceResult) Values() []string {
+	return append([]string{r.Original, r.Hash, strconv.FormatUint(r.Nonce, 10), r.Mined}, r.Receipt.values()...)
+}
+
+func (r replaceResult) Lines() []string {
+	lines := []string{msgs.Sprintf(i18n.ReplacementSent, r.Hash, r.Nonce, r.fees)}
+	if r.Mined != "" {
+		lines = append(lines, msgs.Sprintf(i18n.ReplacementMined, r.Mined))
+	}
+	return append(lines, r.Receipt.lines()...)
+}
+
+// replaceTx 对 pending 交易加价重发（speedup）或替换为自转账（cancel）
+func replaceTx(name string, args []string, cancel bool) error {
+	var g globalFlags
+	fs := newFlagSet("tx "+name, &g)
+	g.tx.register(fs)
+	bump := fs.Float64("bump", txmgr.DefaultPriceBump, msgs.Sprintf(i18n.FlagBump))
+	hashArg, err := g.parseArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(hashArg) != 66 || !strings.HasPrefix(hashArg, "0x") {
+		return msgs.Errorf(i18n.ErrTxUsage, name)
+	}
+	hash := common.HexToHash(hashArg)
+
+	client, err := g.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	s, err := g.signer(ctx, client)
+	if err != nil {
+		return err
+	}
+	from := s.Address()
+	chainID := client.ID()
+	nonces, err := g.nonceManager(client)
+	if err != nil {
+		return err
+	}
+	old, pending, err := client.TransactionByHash(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		// 交易可能已被之前的 speedup 替换出交易池，改为处理最新的替换交易
+		for _, tx := range nonces.Inflight(chainID, from) {
+			if slices.Contains(tx.Replaced, hash) {
+				old, pending, err = client.TransactionByHash(ctx, tx.Hash)
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if !pending {
+		return msgs.Errorf(i18n.ErrTxMined, old.Hash().Hex())
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), old)
+	if err != nil {
+		return err
+	}
+	if sender != from {
+		return msgs.Errorf(i18n.ErrTxSender, old.Hash().Hex(), sender.Hex(), from.Hex())
+	}
+
+	suggested, err := g.fees(ctx, client)
+	if err != nil {
+		return err
+	}
+	fees, err := txmgr.BumpFees(old, suggested, *bump)
+	if err != nil {
+		return err
+	}
+	replacement := txmgr.SpeedUp(old, fees, chainID)
+	if cancel {
+		replacement = txmgr.Cancel(old, from, fees, chainID)
+	}
+	signed, err := s.SignTx(ctx, replacement, chainID)
+	if err != nil {
+		return err
+	}
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		return err
+	}
+
+	// 记录替换关系，之后再次加价时也能等待到更早的交易
+	hashes := []common.Hash{signed.Hash(), old.Hash()}
+	if err := nonces.Replaced(chainID, from, old.Hash(), signed); err != nil {
+		// 替换交易已发出，只是在途记录写入失败
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.Warning, err))
+	} else {
+		hashes = nonces.Inflight(chainID, from)[signed.Nonce()].Hashes()
+	}
+
+	res := replaceResult{
+		Original: old.Hash().Hex(),
+		Hash:     signed.Hash().Hex(),
+		Nonce:    signed.Nonce(),
+		fees:     fees.String(),
+	}
+	res.Receipt, err = g.wait(client, hashes...)
+	if res.Receipt != nil {
+		res.Mined = res.Receipt.TxHash
+	}
+	return g.printTx(res, err)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"sepolia-block/counter"
+	"sepolia-block/i18n"
+	"sepolia-block/verify"
+)
+
+// verifyResult 字节码核对结果
+type verifyResult struct {
+	Address          string   `json:"address"`
+	Status           string   `json:"status"`
+	Compiler         string   `json:"compiler"`          // 嵌入字节码的编译器版本
+	DeployedCompiler string   `json:"deployed_compiler"` // 链上代码元数据中的编译器版本
+	CodeSize         int      `json:"code_size"`
+	Immutables       []string `json:"immutables"` // offset=value
+
+	status verify.Status
+}
+
+func newVerifyResult(res *verify.Result) verifyResult {
+	r := verifyResult{
+		Address:    res.Address.Hex(),
+		Status:     res.Status.String(),
+		CodeSize:   res.CodeSize,
+		Immutables: []string{},
+		status:     res.Status,
+	}
+	if res.Expected != nil {
+		r.Compiler = res.Expected.Compiler
+	}
+	if res.Deployed != nil {
+		r.DeployedCompiler = res.Deployed.Compiler
+	}
+	for _, im := range res.Immutables {
+		r.Immutables = append(r.Immutables, strconv.Itoa(im.Offset)+"="+im.Value.Hex())
+	}
+	return r
+}
+
+func (r verifyResult) Columns() []string {
+	return []string{"address", "status", "compiler", "deployed_compiler", "code_size", "immutables"}
+}
+
+func (r verifyResult) Values() []string {
+	return []string{
+		r.Address,
+		r.Status,
+		r.Compiler,
+		r.DeployedCompiler,
+		strconv.Itoa(r.CodeSize),
+		strings.Join(r.Immutables, " "),
+	}
+}
+
+func (r verifyResult) Lines() []string {
+	var lines []string
+	switch r.status {
+	case verify.FullMatch:
+		lines = append(lines, msgs.Sprintf(i18n.VerifyFullMatch, r.Address, r.Compiler))
+	case verify.PartialMatch:
+		lines = append(lines, msgs.Sprintf(i18n.VerifyPartialMatch, r.Address, r.Compiler, cmp.Or(r.DeployedCompiler, "?")))
+	case verify.Mismatch:
+		lines = append(lines, msgs.Sprintf(i18n.VerifyMismatch, r.Address, r.CodeSize, cmp.Or(r.DeployedCompiler, "?")))
+	default:
+		lines = append(lines, msgs.Sprintf(i18n.VerifyNoCode, r.Address))
+	}
+	for _, im := range r.Immutables {
+		offset, value, _ := strings.Cut(im, "=")
+		lines = append(lines, msgs.Sprintf(i18n.VerifyImmutable, offset, value))
+	}
+	return lines
+}
+
+// runCounterVerify 核对 -address 上的代码是否为 Counter，不一致时返回错误
+func runCounterVerify(args []string) error {
+	var f counterFlags
+	fs := newCounterFlagSet("verify", &f)
+	block := fs.Int64("block", -1, msgs.Sprintf(i18n.FlagVerifyBlock))
+	if err := f.parse(fs, args); err != nil {
+		return err
+	}
+	client, err := f.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var number *big.Int
+	if *block >= 0 {
+		number = big.NewInt(*block)
+	}
+	res, err := verify.Verify(context.Background(), client, common.HexToAddress(f.address), counter.CounterMetaData.Bin, number)
+	if err != nil {
+		return err
+	}
+	if err := f.print(newVerifyResult(res)); err != nil {
+		return err
+	}
+	if !res.Status.OK() {
+		return msgs.Errorf(i18n.ErrNotCounter, f.address)
+	}
+	return nil
+}
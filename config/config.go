@@ -0,0 +1,357 @@
+// Package config 负责加载网络配置：内置默认值 → 配置文件 → 环境变量，
+// 命令行参数最后由调用方覆盖。
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultPath 未指定配置文件时在当前目录查找的文件名
+	DefaultPath = "sepolia-block.yaml"
+	// EnvPrefix 环境变量前缀，例如 SEPOLIA_BLOCK_NETWORK
+	EnvPrefix = "SEPOLIA_BLOCK_"
+	// DefaultCounter 地址簿中默认 Counter 实例的名字
+	DefaultCounter = "default"
+)
+
+// Network 单个网络的配置
+type Network struct {
+	Name      string            `yaml:"-"`
+	RPC       string            `yaml:"rpc,omitempty"` // 逗号分隔多个节点时自动切换
+	ChainID   uint64            `yaml:"chain_id,omitempty"`
+	RateLimit float64           `yaml:"rate_limit,omitempty"` // 每个节点每秒请求数上限，0 表示不限
+	Counters  map[string]string `yaml:"counters,omitempty"`   // 已部署的 Counter 地址簿
+}
+
+// Config 完整配置
+type Config struct {
+	Network     string              `yaml:"network,omitempty"`      // 默认使用的网络
+	Key         string              `yaml:"key,omitempty"`          // 私钥来源
+	Output      string              `yaml:"output,omitempty"`       // 输出格式
+	FeeStrategy string              `yaml:"fee_strategy,omitempty"` // 手续费策略：slow、normal、fast、custom
+	Simulate    *bool               `yaml:"simulate,omitempty"`     // 发送前是否先用 eth_call 模拟
+	Networks    map[string]*Network `yaml:"networks,omitempty"`
+
+	path string     // 加载来源，Save 时写回
+	file *Config    // 配置文件本身的内容，Save 只写回这一层，不含默认值和环境变量
+	doc  *yaml.Node // 配置文件的语法树，Save 在其上修改以保留注释和顺序
+
+	envRPC     string // SEPOLIA_BLOCK_RPC，在 Profile 中作用于选中的网络
+	envChainID uint64 // SEPOLIA_BLOCK_CHAIN_ID，同上
+}
+
+// Default 返回内置的网络配置
+func Default() *Config {
+	return &Config{
+		Network:     "sepolia",
+		Key:         "env:private_key",
+		Output:      "text",
+		FeeStrategy: "normal",
+		Simulate:    ptr(true),
+		Networks: map[string]*Network{
+			"sepolia": {
+				RPC:       "https://1rpc.io/sepolia,https://ethereum-sepolia-rpc.publicnode.com",
+				ChainID:   11155111,
+				RateLimit: 10,
+				Counters: map[string]string{
+					DefaultCounter: "0xe09d7Ce1107Dc37C9c20d8019DD1786Ca82F6640",
+				},
+			},
+			"anvil": {
+				RPC:     "http://127.0.0.1:8545",
+				ChainID: 31337,
+			},
+			"mainnet-fork": {
+				RPC:     "http://127.0.0.1:8545",
+				ChainID: 1,
+			},
+		},
+	}
+}
+
+// Load 加载配置。path 为空时依次尝试 $SEPOLIA_BLOCK_CONFIG 和 DefaultPath，
+// 文件不存在时只使用默认值；显式指定的文件必须存在。
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	explicit := path != ""
+	if !explicit {
+		path = os.Getenv(EnvPrefix + "CONFIG")
+		explicit = path != ""
+	}
+	if path == "" {
+		path = DefaultPath
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		return nil, fmt.Errorf("config %s: TOML is not supported, use a YAML file (see sepolia-block.example.yaml)", path)
+	}
+	cfg.path = path
+	cfg.file = new(Config)
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		if err := doc.Decode(cfg.file); err != nil && doc.Kind != 0 {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		cfg.doc = &doc
+		cfg.merge(cfg.file)
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+	default:
+		return nil, err
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// merge 用 src 中非零的字段覆盖 c
+func (c *Config) merge(src *Config) {
+	if src.Network != "" {
+		c.Network = src.Network
+	}
+	if src.Key != "" {
+		c.Key = src.Key
+	}
+	if src.Output != "" {
+		c.Output = src.Output
+	}
+	if src.FeeStrategy != "" {
+		c.FeeStrategy = src.FeeStrategy
+	}
+	if src.Simulate != nil {
+		c.Simulate = src.Simulate
+	}
+	for name, n := range src.Networks {
+		if n == nil {
+			continue
+		}
+		dst, ok := c.Networks[name]
+		if !ok {
+			dst = new(Network)
+			c.Networks[name] = dst
+		}
+		if n.RPC != "" {
+			dst.RPC = n.RPC
+		}
+		if n.ChainID != 0 {
+			dst.ChainID = n.ChainID
+		}
+		if n.RateLimit != 0 {
+			dst.RateLimit = n.RateLimit
+		}
+		for k, addr := range n.Counters {
+			if dst.Counters == nil {
+				dst.Counters = make(map[string]string)
+			}
+			dst.Counters[k] = addr
+		}
+	}
+}
+
+// applyEnv 环境变量覆盖：NETWORK、KEY、OUTPUT、FEE_STRATEGY、SIMULATE 作用于全局；
+// RPC、CHAIN_ID 先记下，由 Profile 作用于最终选中的网络（可能由 -network 指定）
+func (c *Config) applyEnv() error {
+	if v := os.Getenv(EnvPrefix + "NETWORK"); v != "" {
+		c.Network = v
+	}
+	if v := os.Getenv(EnvPrefix + "KEY"); v != "" {
+		c.Key = v
+	}
+	if v := os.Getenv(EnvPrefix + "OUTPUT"); v != "" {
+		c.Output = v
+	}
+	if v := os.Getenv(EnvPrefix + "FEE_STRATEGY"); v != "" {
+		c.FeeStrategy = v
+	}
+	if v := os.Getenv(EnvPrefix + "SIMULATE"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %sSIMULATE %q: %w", EnvPrefix, v, err)
+		}
+		c.Simulate = &b
+	}
+	c.envRPC = os.Getenv(EnvPrefix + "RPC")
+	if v := os.Getenv(EnvPrefix + "CHAIN_ID"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %sCHAIN_ID %q: %w", EnvPrefix, v, err)
+		}
+		c.envChainID = id
+	}
+	return nil
+}
+
+// Profile 返回指定网络的配置副本，name 为空时使用默认网络。
+// SEPOLIA_BLOCK_RPC 和 SEPOLIA_BLOCK_CHAIN_ID 覆盖这里返回的网络；设置了 RPC 时也可以使用未定义的网络名。
+func (c *Config) Profile(name string) (Network, error) {
+	if name == "" {
+		name = c.Network
+	}
+	var profile Network
+	if n, ok := c.Networks[name]; ok {
+		profile = *n
+	} else if c.envRPC == "" {
+		return Network{}, fmt.Errorf("unknown network %q (available: %v)", name, c.names())
+	}
+	profile.Name = name
+	if c.envRPC != "" {
+		profile.RPC = c.envRPC
+	}
+	if c.envChainID != 0 {
+		profile.ChainID = c.envChainID
+	}
+	return profile, nil
+}
+
+func (c *Config) names() []string {
+	return sortedKeys(c.Networks)
+}
+
+// SetCounter 在地址簿中记录某个网络上的 Counter 实例，同时记入配置文件层以便 Save 写回
+func (c *Config) SetCounter(network, name string, addr common.Address) {
+	setCounter(c, network, name, addr)
+	if c.file == nil {
+		c.file = new(Config)
+	}
+	setCounter(c.file, network, name, addr)
+}
+
+func setCounter(c *Config, network, name string, addr common.Address) {
+	if c.Networks == nil {
+		c.Networks = make(map[string]*Network)
+	}
+	n, ok := c.Networks[network]
+	if !ok || n == nil {
+		n = new(Network)
+		c.Networks[network] = n
+	}
+	if n.Counters == nil {
+		n.Counters = make(map[string]string)
+	}
+	n.Counters[name] = addr.Hex()
+}
+
+// Path 配置文件路径
+func (c *Config) Path() string {
+	if c.path == "" {
+		return DefaultPath
+	}
+	return c.path
+}
+
+// Save 把配置文件层（原有内容加上 SetCounter 的改动）写回文件。默认值、环境变量和命令行参数
+// 不会写入，尤其是来自 SEPOLIA_BLOCK_KEY 的私钥来源；原文件的注释和键的顺序保持不变，文件权限为 0600。
+func (c *Config) Save() error {
+	doc := c.doc
+	if doc == nil || doc.Kind == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("save %s: top level is not a mapping", c.Path())
+	}
+	if c.file != nil {
+		for _, network := range sortedKeys(c.file.Networks) {
+			n := c.file.Networks[network]
+			if n == nil || len(n.Counters) == 0 {
+				continue
+			}
+			counters := mapping(mapping(mapping(root, "networks"), network), "counters")
+			for _, name := range sortedKeys(n.Counters) {
+				setScalar(counters, name, n.Counters[name])
+			}
+		}
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	c.doc = doc
+	if err := os.WriteFile(c.Path(), buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	// WriteFile 不修改已有文件的权限
+	return os.Chmod(c.Path(), 0o600)
+}
+
+// mapping 返回映射节点 m 中 key 对应的映射，不存在或为空值时新建
+func mapping(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != key {
+			continue
+		}
+		v := m.Content[i+1]
+		if v.Kind != yaml.MappingNode {
+			*v = yaml.Node{Kind: yaml.MappingNode, HeadComment: v.HeadComment, LineComment: v.LineComment}
+		}
+		return v
+	}
+	v := &yaml.Node{Kind: yaml.MappingNode}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return v
+}
+
+// setScalar 把映射节点 m 中 key 的值设为 value，值未变时不动原节点
+func setScalar(m *yaml.Node, key, value string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != key {
+			continue
+		}
+		if v := m.Content[i+1]; v.Kind != yaml.ScalarNode || v.Value != value {
+			*v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, LineComment: v.LineComment}
+		}
+		return
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Counter 按名字或十六进制地址解析 Counter 实例
+func (n Network) Counter(ref string) (common.Address, error) {
+	if ref == "" {
+		ref = DefaultCounter
+	}
+	if common.IsHexAddress(ref) {
+		return common.HexToAddress(ref), nil
+	}
+	if addr, ok := n.Counters[ref]; ok {
+		if !common.IsHexAddress(addr) {
+			return common.Address{}, fmt.Errorf("invalid address %q for counter %q on network %s", addr, ref, n.Name)
+		}
+		return common.HexToAddress(addr), nil
+	}
+	return common.Address{}, fmt.Errorf("no counter %q in address book of network %s", ref, n.Name)
+}
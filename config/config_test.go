@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSaveKeepsEnvOutOfFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sepolia-block.yaml")
+	if err := os.WriteFile(path, []byte("network: anvil\nnetworks:\n  anvil:\n    counters:\n      old: 0x0000000000000000000000000000000000000001\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	const secret = "hex:ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	t.Setenv(EnvPrefix+"KEY", secret)
+	t.Setenv(EnvPrefix+"RPC", "http://10.0.0.1:8545")
+	t.Setenv(EnvPrefix+"CHAIN_ID", "42")
+	t.Setenv(EnvPrefix+"OUTPUT", "json")
+	t.Setenv(EnvPrefix+"FEE_STRATEGY", "fast")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := cfg.Profile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Key != secret || profile.RPC != "http://10.0.0.1:8545" {
+		t.Fatalf("environment not applied: key %q, rpc %q", cfg.Key, profile.RPC)
+	}
+	addr := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	cfg.SetCounter("anvil", "new", addr)
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"ac0974", "key:", "10.0.0.1", "chain_id", "output", "fee_strategy", "sepolia"} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("saved config contains %q:\n%s", leaked, data)
+		}
+	}
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	counters := saved.Networks["anvil"].Counters
+	if saved.Network != "anvil" || counters["old"] == "" || counters["new"] != addr.Hex() {
+		t.Errorf("saved config lost file settings or the new counter:\n%s", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("config file mode %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestEnvAppliesToSelectedNetwork(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sepolia-block.yaml")
+	if err := os.WriteFile(path, []byte("network: anvil\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvPrefix+"RPC", "http://10.0.0.1:8545")
+	t.Setenv(EnvPrefix+"CHAIN_ID", "42")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 相当于 -network sepolia：环境变量作用于 sepolia，而不是配置文件里的默认网络 anvil
+	sepolia, err := cfg.Profile("sepolia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sepolia.RPC != "http://10.0.0.1:8545" || sepolia.ChainID != 42 {
+		t.Errorf("sepolia profile = %q, %d, want the environment override", sepolia.RPC, sepolia.ChainID)
+	}
+	if sepolia.Counters[DefaultCounter] == "" {
+		t.Errorf("sepolia profile lost its address book: %v", sepolia.Counters)
+	}
+	if n := cfg.Networks["anvil"]; n.RPC != "http://127.0.0.1:8545" || n.ChainID != 31337 {
+		t.Errorf("anvil network changed to %q, %d", n.RPC, n.ChainID)
+	}
+}
+
+func TestSaveKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sepolia-block.yaml")
+	const original = `# 本地开发配置
+network: anvil # 默认网络
+networks:
+  anvil:
+    rpc: http://127.0.0.1:8545
+    # 已部署的实例
+    counters:
+      old: "0x0000000000000000000000000000000000000001" # 第一次部署
+`
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	cfg.SetCounter("anvil", "new", addr)
+	cfg.SetCounter("sepolia", "mine", addr)
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, comment := range []string{"# 本地开发配置", "# 默认网络", "# 已部署的实例", "# 第一次部署"} {
+		if !strings.Contains(string(data), comment) {
+			t.Errorf("saved config lost comment %q:\n%s", comment, data)
+		}
+	}
+	if !strings.HasPrefix(string(data), original[:strings.Index(original, "      old")]) {
+		t.Errorf("saved config reordered existing keys:\n%s", data)
+	}
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Networks["anvil"].Counters["new"] != addr.Hex() || reloaded.Networks["sepolia"].Counters["mine"] != addr.Hex() {
+		t.Errorf("saved config lost the new counters:\n%s", data)
+	}
+}
+
+func TestLoadRejectsTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sepolia-block.toml")
+	if err := os.WriteFile(path, []byte("network = \"anvil\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "TOML is not supported") {
+		t.Errorf("Load(%s) = %v, want a TOML error", path, err)
+	}
+}
@@ -0,0 +1,343 @@
+package counter_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+
+	"sepolia-block/counter"
+	"sepolia-block/revert"
+	"sepolia-block/txmgr"
+)
+
+// testEnv 进程内的模拟链和已部署的 Counter，不依赖任何外部节点
+type testEnv struct {
+	sim     *simulated.Backend
+	client  simulated.Client
+	auth    *bind.TransactOpts
+	address common.Address
+	counter *counter.Counter
+}
+
+// newTestEnv 创建带资金账户的模拟链，通过 DeployCounter 部署 Counter。
+// wrap 可选，用于在模拟链的客户端外包装后端（例如 revert.Decoder.Wrap）。
+func newTestEnv(t *testing.T, wrap func(bind.ContractBackend) bind.ContractBackend) *testEnv {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	balance := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	sim := simulated.NewBackend(types.GenesisAlloc{from: {Balance: balance}})
+	t.Cleanup(func() { sim.Close() })
+
+	client := sim.Client()
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var backend bind.ContractBackend = client
+	if wrap != nil {
+		backend = wrap(backend)
+	}
+	address, tx, c, err := counter.DeployCounter(auth, backend)
+	if err != nil {
+		t.Fatalf("DeployCounter: %v", err)
+	}
+	sim.Commit()
+	env := &testEnv{sim: sim, client: client, auth: auth, address: address, counter: c}
+	if receipt := env.receipt(t, tx); receipt.ContractAddress != address {
+		t.Fatalf("receipt contract address %s, want %s", receipt.ContractAddress.Hex(), address.Hex())
+	}
+	return env
+}
+
+// receipt 返回已打包交易的回执
+func (e *testEnv) receipt(t *testing.T, tx *types.Transaction) *types.Receipt {
+	t.Helper()
+	receipt, err := e.client.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatalf("receipt of %s: %v", tx.Hash().Hex(), err)
+	}
+	return receipt
+}
+
+// mine 发送交易并出块，要求执行成功
+func (e *testEnv) mine(t *testing.T, send func(*bind.TransactOpts) (*types.Transaction, error)) *types.Receipt {
+	t.Helper()
+	tx, err := send(e.auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.sim.Commit()
+	receipt := e.receipt(t, tx)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("transaction %s failed", tx.Hash().Hex())
+	}
+	return receipt
+}
+
+// x 读取最新区块上的计数
+func (e *testEnv) x(t *testing.T) *big.Int {
+	t.Helper()
+	x, err := e.counter.X(&bind.CallOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func TestDeployCounter(t *testing.T) {
+	env := newTestEnv(t, nil)
+	code, err := env.client.CodeAt(context.Background(), env.address, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) == 0 {
+		t.Fatal("no code at deployed address")
+	}
+	if x := env.x(t); x.Sign() != 0 {
+		t.Errorf("x = %s after deployment, want 0", x)
+	}
+}
+
+func TestIncAndIncBy(t *testing.T) {
+	env := newTestEnv(t, nil)
+	env.mine(t, env.counter.Inc)
+	if x := env.x(t); x.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("x = %s after Inc, want 1", x)
+	}
+	env.mine(t, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return env.counter.IncBy(opts, big.NewInt(41))
+	})
+	if x := env.x(t); x.Cmp(big.NewInt(42)) != 0 {
+		t.Fatalf("x = %s after IncBy(41), want 42", x)
+	}
+
+	// 历史区块上的值不受之后交易影响
+	head, err := env.client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := env.counter.X(&bind.CallOpts{BlockNumber: new(big.Int).SetUint64(head - 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("x at block %d = %s, want 1", head-1, x)
+	}
+}
+
+func TestParseIncrement(t *testing.T) {
+	env := newTestEnv(t, nil)
+	receipt := env.mine(t, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return env.counter.IncBy(opts, big.NewInt(7))
+	})
+	if len(receipt.Logs) != 1 {
+		t.Fatalf("got %d logs, want 1", len(receipt.Logs))
+	}
+	ev, err := env.counter.ParseIncrement(*receipt.Logs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.By.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("by = %s, want 7", ev.By)
+	}
+	if ev.Raw.TxHash != receipt.TxHash || ev.Raw.Address != env.address {
+		t.Errorf("raw log %s@%s, want %s@%s", ev.Raw.TxHash.Hex(), ev.Raw.Address.Hex(), receipt.TxHash.Hex(), env.address.Hex())
+	}
+
+	// 其他事件的日志不能被解析为 Increment
+	other := *receipt.Logs[0]
+	other.Topics = []common.Hash{crypto.Keccak256Hash([]byte("Other(uint256)"))}
+	if _, err := env.counter.ParseIncrement(other); err == nil {
+		t.Error("ParseIncrement accepted a log with a different event signature")
+	}
+}
+
+func TestFilterIncrement(t *testing.T) {
+	env := newTestEnv(t, nil)
+	var blocks []uint64
+	for _, by := range []int64{1, 2, 3} {
+		receipt := env.mine(t, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return env.counter.IncBy(opts, big.NewInt(by))
+		})
+		blocks = append(blocks, receipt.BlockNumber.Uint64())
+	}
+
+	tests := []struct {
+		name     string
+		from, to uint64
+		want     []int64
+	}{
+		{"all", 0, blocks[2], []int64{1, 2, 3}},
+		{"first block only", blocks[0], blocks[0], []int64{1}},
+		{"last two", blocks[1], blocks[2], []int64{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it, err := env.counter.FilterIncrement(&bind.FilterOpts{Start: tt.from, End: &tt.to})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer it.Close()
+			var got []int64
+			for it.Next() {
+				if n := it.Event.Raw.BlockNumber; n < tt.from || n > tt.to {
+					t.Errorf("event in block %d outside %d-%d", n, tt.from, tt.to)
+				}
+				got = append(got, it.Event.By.Int64())
+			}
+			if err := it.Error(); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchIncrement(t *testing.T) {
+	env := newTestEnv(t, nil)
+	sink := make(chan *counter.CounterIncrement, 4)
+	sub, err := env.counter.WatchIncrement(&bind.WatchOpts{Context: context.Background()}, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	env.mine(t, env.counter.Inc)
+	env.mine(t, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return env.counter.IncBy(opts, big.NewInt(5))
+	})
+
+	for _, want := range []int64{1, 5} {
+		select {
+		case ev := <-sink:
+			if ev.By.Int64() != want {
+				t.Errorf("by = %s, want %d", ev.By, want)
+			}
+			if ev.Raw.Removed {
+				t.Error("unexpected removed log")
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for Increment(by=%d)", want)
+		}
+	}
+}
+
+func TestIncByZeroReverts(t *testing.T) {
+	decoder, err := revert.NewDecoder(counter.CounterMetaData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := newTestEnv(t, decoder.Wrap)
+
+	// gas 估算即模拟执行，回滚原因在发送前返回
+	_, err = env.counter.IncBy(env.auth, big.NewInt(0))
+	var reason *revert.ReasonError
+	if !errors.As(err, &reason) {
+		t.Fatalf("IncBy(0) error %v (%T), want *revert.ReasonError", err, err)
+	}
+	if want := "incBy: increment should be positive"; reason.Reason != want {
+		t.Errorf("reason %q, want %q", reason.Reason, want)
+	}
+
+	// 跳过估算直接发送，交易上链但执行失败，x 不变
+	opts := *env.auth
+	opts.GasLimit = 100_000
+	tx, err := env.counter.IncBy(&opts, big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.sim.Commit()
+	if receipt := env.receipt(t, tx); receipt.Status != types.ReceiptStatusFailed {
+		t.Error("IncBy(0) succeeded on chain")
+	}
+	if x := env.x(t); x.Sign() != 0 {
+		t.Errorf("x = %s after reverted IncBy(0), want 0", x)
+	}
+}
+
+func TestIncOverflowPanics(t *testing.T) {
+	decoder, err := revert.NewDecoder(counter.CounterMetaData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := newTestEnv(t, decoder.Wrap)
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	env.mine(t, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return env.counter.IncBy(opts, maxUint256)
+	})
+
+	_, err = env.counter.Inc(env.auth)
+	var panicErr *revert.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Inc at max error %v (%T), want *revert.PanicError", err, err)
+	}
+	if panicErr.Code.Int64() != revert.PanicOverflow {
+		t.Errorf("panic code %#x, want %#x", panicErr.Code, revert.PanicOverflow)
+	}
+}
+
+func TestSimulateBeforeSend(t *testing.T) {
+	decoder, err := revert.NewDecoder(counter.CounterMetaData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sim *txmgr.Simulator
+	env := newTestEnv(t, func(backend bind.ContractBackend) bind.ContractBackend {
+		sim = txmgr.NewSimulator(backend.(bind.PendingContractCaller), decoder)
+		return sim.Wrap(backend)
+	})
+
+	raw := &counter.CounterRaw{Contract: env.counter}
+
+	// 固定 gas limit 跳过估算，只有发送前的模拟能拦住必然回滚的交易
+	opts := *env.auth
+	opts.GasLimit = 100_000
+	_, err = raw.Transact(&opts, "incBy", big.NewInt(0))
+	var simErr *txmgr.SimulationError
+	if !errors.As(err, &simErr) {
+		t.Fatalf("Transact error %v (%T), want *txmgr.SimulationError", err, err)
+	}
+	var reason *revert.ReasonError
+	if !errors.As(err, &reason) {
+		t.Fatalf("simulation error %v does not carry the revert reason", err)
+	}
+	if pending, err := env.client.PendingTransactionCount(context.Background()); err != nil || pending != 0 {
+		t.Fatalf("%d pending transactions (%v), want none", pending, err)
+	}
+
+	// 单次调用关闭模拟后照常发送
+	opts.Context = txmgr.WithSimulation(context.Background(), false)
+	tx, err := raw.Transact(&opts, "incBy", big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.sim.Commit()
+	if receipt := env.receipt(t, tx); receipt.Status != types.ReceiptStatusFailed {
+		t.Error("IncBy(0) succeeded on chain")
+	}
+}
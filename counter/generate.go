@@ -0,0 +1,7 @@
+package counter
+
+// counter.go 由 tools/bindgen 从 Foundry 构建产物（out/Counter.sol/Counter.json）生成，修改 Counter.sol 后：
+//
+//	forge build && go generate ./counter
+//
+//go:generate go run ../tools/bindgen -C ..
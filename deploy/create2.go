@@ -0,0 +1,90 @@
+// Package deploy 部署 Counter 合约：通过 CREATE2 工厂确定性部署，地址只取决于工厂、salt 和创建字节码，
+// 与部署账户的 nonce 无关，在所有链上都相同。
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DeterministicProxy 通用的确定性部署代理（Arachnid/deterministic-deployment-proxy），
+// 以无链 ID 的预签名交易部署，在绝大多数链上地址相同。
+// 调用数据为 salt（32 字节）紧接创建字节码，成功时返回新合约地址。
+var DeterministicProxy = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+// ErrNoFactory 链上没有 CREATE2 工厂
+var ErrNoFactory = errors.New("CREATE2 factory is not deployed on this chain")
+
+// Create2 一次 CREATE2 部署的参数
+type Create2 struct {
+	Factory  common.Address
+	Salt     common.Hash
+	InitCode []byte // 创建字节码，带构造参数时已拼接在末尾
+}
+
+// NewCreate2 通过确定性部署代理部署 bin（十六进制创建字节码）
+func NewCreate2(bin string, salt common.Hash) (Create2, error) {
+	code, err := hexutil.Decode(bin)
+	if err != nil {
+		return Create2{}, fmt.Errorf("decode bytecode: %w", err)
+	}
+	return Create2{Factory: DeterministicProxy, Salt: salt, InitCode: code}, nil
+}
+
+// Address 预测部署地址：keccak256(0xff ++ factory ++ salt ++ keccak256(initCode)) 的后 20 字节
+func (c Create2) Address() common.Address {
+	return crypto.CreateAddress2(c.Factory, c.Salt, crypto.Keccak256(c.InitCode))
+}
+
+// calldata 工厂的调用数据
+func (c Create2) calldata() []byte {
+	return append(c.Salt.Bytes(), c.InitCode...)
+}
+
+// ParseSalt 解析 salt：0x 开头的 32 字节十六进制直接使用，其他字符串取 keccak256，
+// 便于用 "counter-v1" 这样的名字作为 salt。
+func ParseSalt(s string) (common.Hash, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		b, err := hexutil.Decode(s)
+		if err != nil || len(b) != common.HashLength {
+			return common.Hash{}, fmt.Errorf("invalid salt %q: want 32 bytes of hex", s)
+		}
+		return common.BytesToHash(b), nil
+	}
+	return crypto.Keccak256Hash([]byte(s)), nil
+}
+
+// Deploy2 通过 CREATE2 工厂部署合约。预测地址上已有代码时不发送交易，tx 为 nil。
+// 交易发出后即返回，调用方负责等待回执并确认预测地址上出现了代码。
+func Deploy2(ctx context.Context, opts *bind.TransactOpts, backend bind.ContractBackend, c Create2) (common.Address, *types.Transaction, error) {
+	address := c.Address()
+	code, err := backend.CodeAt(ctx, address, nil)
+	if err != nil {
+		return address, nil, err
+	}
+	if len(code) > 0 {
+		return address, nil, nil
+	}
+	factory, err := backend.CodeAt(ctx, c.Factory, nil)
+	if err != nil {
+		return address, nil, err
+	}
+	if len(factory) == 0 {
+		return address, nil, fmt.Errorf("%w: no code at %s", ErrNoFactory, c.Factory.Hex())
+	}
+	contract := bind.NewBoundContract(c.Factory, abi.ABI{}, backend, backend, backend)
+	tx, err := contract.RawTransact(opts, c.calldata())
+	if err != nil {
+		return address, nil, err
+	}
+	return address, tx, nil
+}
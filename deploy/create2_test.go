@@ -0,0 +1,45 @@
+package deploy
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCreate2Address(t *testing.T) {
+	// EIP-1014 示例 0
+	c := Create2{InitCode: []byte{0x00}}
+	want := common.HexToAddress("0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38")
+	if got := c.Address(); got != want {
+		t.Errorf("address %s, want %s", got.Hex(), want.Hex())
+	}
+	if data := c.calldata(); len(data) != common.HashLength+1 {
+		t.Errorf("calldata has %d bytes, want %d", len(data), common.HashLength+1)
+	}
+}
+
+func TestParseSalt(t *testing.T) {
+	hex := "0x00000000000000000000000000000000000000000000000000000000000000ff"
+	tests := []struct {
+		in      string
+		want    common.Hash
+		wantErr bool
+	}{
+		{hex, common.HexToHash(hex), false},
+		{"counter-v1", crypto.Keccak256Hash([]byte("counter-v1")), false},
+		{"", crypto.Keccak256Hash(nil), false},
+		{"0x01", common.Hash{}, true},
+		{"0xzz", common.Hash{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSalt(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSalt(%q) error %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSalt(%q) = %s, want %s", tt.in, got.Hex(), tt.want.Hex())
+		}
+	}
+}
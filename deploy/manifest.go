@@ -0,0 +1,175 @@
+package deploy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// ManifestVersion 当前的清单格式版本，格式不兼容地变化时递增
+	ManifestVersion = 1
+	// DefaultManifest 未指定清单文件时使用的文件名，建议提交到版本库
+	DefaultManifest = "deployments.json"
+)
+
+// ErrManifestVersion 清单由更新版本的工具写入，无法安全地读取和改写
+var ErrManifestVersion = errors.New("unsupported manifest version")
+
+// Deployment 一次部署的记录
+type Deployment struct {
+	Network      string          `json:"network"`
+	ChainID      uint64          `json:"chain_id"`
+	Name         string          `json:"name,omitempty"` // 地址簿中的名字，未命名的部署为空
+	Address      common.Address  `json:"address"`
+	TxHash       common.Hash     `json:"tx_hash"`
+	Block        uint64          `json:"block,omitempty"` // 未等待回执时为 0
+	Deployer     common.Address  `json:"deployer"`
+	BytecodeHash common.Hash     `json:"bytecode_hash"`     // 创建字节码的 keccak256
+	Factory      *common.Address `json:"factory,omitempty"` // CREATE2 工厂，普通部署为空
+	Salt         *common.Hash    `json:"salt,omitempty"`
+	Time         time.Time       `json:"time"`
+}
+
+// MigrationRecord 某个网络上已执行（或已发出交易、尚未确认）的迁移
+type MigrationRecord struct {
+	Network string          `json:"network"`
+	ChainID uint64          `json:"chain_id"`
+	ID      string          `json:"id"`
+	TxHash  common.Hash     `json:"tx_hash,omitempty"`
+	Block   uint64          `json:"block,omitempty"`
+	Pending bool            `json:"pending,omitempty"` // 交易已发出但未确认，重新运行时等待这笔交易而不是重发
+	From    *common.Address `json:"from,omitempty"`    // pending 交易的发送方
+	Nonce   *uint64         `json:"nonce,omitempty"`   // pending 交易的 nonce，发送方的 nonce 越过它而交易没有上链时重新发送
+	Time    time.Time       `json:"time"`
+}
+
+// Manifest 部署清单：所有网络上的部署记录和迁移执行记录，按时间顺序追加
+type Manifest struct {
+	Version     int               `json:"version"`
+	Deployments []Deployment      `json:"deployments"`
+	Migrations  []MigrationRecord `json:"migrations"`
+
+	path string
+}
+
+// LoadManifest 读取清单，文件不存在时返回空清单
+func LoadManifest(path string) (*Manifest, error) {
+	m := &Manifest{Version: ManifestVersion, Deployments: []Deployment{}, Migrations: []MigrationRecord{}, path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	if m.Version > ManifestVersion || m.Version < 1 {
+		return nil, fmt.Errorf("%w %d in %s (supported: %d)", ErrManifestVersion, m.Version, path, ManifestVersion)
+	}
+	return m, nil
+}
+
+// Path 清单文件路径
+func (m *Manifest) Path() string {
+	return m.path
+}
+
+// Save 把清单原子地写回文件
+func (m *Manifest) Save() error {
+	m.Version = ManifestVersion
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(m.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
+
+// Check 确认清单中 network 的记录都属于 chainID，避免把另一条链的记录当作已执行
+func (m *Manifest) Check(network string, chainID uint64) error {
+	for _, d := range m.Deployments {
+		if d.Network == network && d.ChainID != chainID {
+			return fmt.Errorf("manifest %s records network %s as chain %d, connected to chain %d", m.path, network, d.ChainID, chainID)
+		}
+	}
+	for _, r := range m.Migrations {
+		if r.Network == network && r.ChainID != chainID {
+			return fmt.Errorf("manifest %s records network %s as chain %d, connected to chain %d", m.path, network, r.ChainID, chainID)
+		}
+	}
+	return nil
+}
+
+// Deployment 返回 network 上名为 name 的最近一次部署
+func (m *Manifest) Deployment(network, name string) (Deployment, bool) {
+	for i := len(m.Deployments) - 1; i >= 0; i-- {
+		if d := m.Deployments[i]; d.Network == network && d.Name == name {
+			return d, true
+		}
+	}
+	return Deployment{}, false
+}
+
+// AddDeployment 追加一条部署记录
+func (m *Manifest) AddDeployment(d Deployment) {
+	if d.Time.IsZero() {
+		d.Time = time.Now().UTC()
+	}
+	m.Deployments = append(m.Deployments, d)
+}
+
+// Migration 返回 network 上迁移 id 的执行记录
+func (m *Manifest) Migration(network, id string) (MigrationRecord, bool) {
+	for _, r := range m.Migrations {
+		if r.Network == network && r.ID == id {
+			return r, true
+		}
+	}
+	return MigrationRecord{}, false
+}
+
+// SetMigration 写入迁移记录，同一网络上相同 id 的记录被替换
+func (m *Manifest) SetMigration(r MigrationRecord) {
+	if r.Time.IsZero() {
+		r.Time = time.Now().UTC()
+	}
+	for i, old := range m.Migrations {
+		if old.Network == r.Network && old.ID == r.ID {
+			m.Migrations[i] = r
+			return
+		}
+	}
+	m.Migrations = append(m.Migrations, r)
+}
+
+// DeleteMigration 删除迁移记录，下次运行时重新执行
+func (m *Manifest) DeleteMigration(network, id string) {
+	for i, r := range m.Migrations {
+		if r.Network == network && r.ID == id {
+			m.Migrations = append(m.Migrations[:i], m.Migrations[i+1:]...)
+			return
+		}
+	}
+}
+
+// BytecodeHash 创建字节码的哈希，用于确认清单中的部署与当前绑定是否同一版本
+func BytecodeHash(code []byte) common.Hash {
+	return crypto.Keccak256Hash(code)
+}
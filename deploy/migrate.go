@@ -0,0 +1,423 @@
+package deploy
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gopkg.in/yaml.v3"
+
+	"sepolia-block/counter"
+	"sepolia-block/txmgr"
+	"sepolia-block/verify"
+)
+
+// DefaultMigrations 未指定迁移脚本时使用的文件名
+const DefaultMigrations = "migrations.yaml"
+
+// 迁移的动作
+const (
+	ActionDeploy = "deploy"
+	ActionIncBy  = "inc_by"
+)
+
+// 迁移的执行结果
+const (
+	StatusApplied = "applied"
+	StatusSkipped = "skipped" // 清单中已有执行记录
+)
+
+// ErrStaleManifest 清单记录的合约在链上已不存在，通常是本地测试链被重置
+var ErrStaleManifest = errors.New("deployment recorded in manifest has no code on chain")
+
+// Migration 迁移脚本中的一步，deploy 与 inc_by 必须且只能设置其一
+type Migration struct {
+	ID     string      `yaml:"id"`
+	Deploy *DeployStep `yaml:"deploy,omitempty"`
+	IncBy  *IncByStep  `yaml:"inc_by,omitempty"`
+}
+
+// DeployStep 部署 Counter，结果以 Name 记入清单
+type DeployStep struct {
+	Name    string `yaml:"name"`
+	Salt    string `yaml:"salt,omitempty"`    // 设置时通过 CREATE2 部署，格式同 ParseSalt
+	Factory string `yaml:"factory,omitempty"` // CREATE2 工厂，默认 DeterministicProxy
+
+	create2 *Create2
+}
+
+// IncByStep 调用 IncBy 写入初始状态
+type IncByStep struct {
+	Counter string `yaml:"counter"` // 清单中的部署名或十六进制地址
+	By      string `yaml:"by"`      // 十进制，可超过 int64 范围
+
+	by *big.Int
+}
+
+// Action 迁移的动作名
+func (m Migration) Action() string {
+	if m.Deploy != nil {
+		return ActionDeploy
+	}
+	return ActionIncBy
+}
+
+// LoadMigrations 读取并校验 YAML 迁移脚本
+func LoadMigrations(path string) ([]Migration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var script struct {
+		Migrations []Migration `yaml:"migrations"`
+	}
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := validate(script.Migrations); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return script.Migrations, nil
+}
+
+// validate 校验迁移并解析其中的 salt、地址和数值
+func validate(migrations []Migration) error {
+	seen := make(map[string]bool)
+	for i, m := range migrations {
+		if m.ID == "" {
+			return fmt.Errorf("migration #%d has no id", i+1)
+		}
+		if seen[m.ID] {
+			return fmt.Errorf("duplicate migration id %q", m.ID)
+		}
+		seen[m.ID] = true
+		if (m.Deploy == nil) == (m.IncBy == nil) {
+			return fmt.Errorf("migration %s: exactly one of deploy and inc_by must be set", m.ID)
+		}
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("migration %s: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+func (m Migration) validate() error {
+	if d := m.Deploy; d != nil {
+		if d.Name == "" {
+			return errors.New("deploy.name is required")
+		}
+		if d.Salt == "" {
+			if d.Factory != "" {
+				return errors.New("deploy.factory requires deploy.salt")
+			}
+			return nil
+		}
+		c, err := NewCreate2(counter.CounterMetaData.Bin, common.Hash{})
+		if err != nil {
+			return err
+		}
+		if c.Salt, err = ParseSalt(d.Salt); err != nil {
+			return err
+		}
+		if d.Factory != "" {
+			if !common.IsHexAddress(d.Factory) {
+				return fmt.Errorf("invalid deploy.factory %q", d.Factory)
+			}
+			c.Factory = common.HexToAddress(d.Factory)
+		}
+		d.create2 = &c
+		return nil
+	}
+	s := m.IncBy
+	if s.Counter == "" {
+		return errors.New("inc_by.counter is required")
+	}
+	by, ok := new(big.Int).SetString(s.By, 10)
+	if !ok || by.Sign() <= 0 {
+		return fmt.Errorf("invalid inc_by.by %q: want a positive decimal integer", s.By)
+	}
+	s.by = by
+	return nil
+}
+
+// Waiter 等待交易确认，*txmgr.Tracker 满足该接口
+type Waiter interface {
+	Wait(ctx context.Context, hash common.Hash) (*txmgr.Result, error)
+}
+
+// ChainReader 判断 pending 交易是否已被丢弃所需的节点接口，*chain.Client 满足
+type ChainReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// Result 一步迁移的结果
+type Result struct {
+	ID      string
+	Action  string
+	Status  string
+	Name    string         // deploy：部署名
+	Address common.Address // deploy：合约地址；inc_by：调用的合约
+	TxHash  common.Hash    // 跳过时为之前执行时的交易，复用已有合约时为空
+	Block   uint64
+}
+
+// Runner 在一个网络上按顺序执行迁移。每一步的执行记录写入清单：
+// 交易发出后先记为 pending，确认后记为完成，因此重复运行或中途失败后重新运行
+// 都不会重复发送已经上链的交易。pending 的交易被节点丢弃、其 nonce 已被其他交易用掉时重新发送。
+type Runner struct {
+	Manifest *Manifest
+	Network  string
+	ChainID  uint64
+	Backend  bind.ContractBackend
+	Opts     *bind.TransactOpts
+	Waiter   Waiter
+	Chain    ChainReader     // 可选，用于发现被丢弃的 pending 交易；为 nil 时总是等待 pending 交易
+	Timeout  time.Duration   // 每笔交易等待确认的超时，0 表示不限
+	OnStep   func(Migration) // 可选，需要执行（而不是跳过）某一步之前回调
+}
+
+// Run 依次执行 migrations，遇到错误时停止，返回已完成的结果和错误
+func (r *Runner) Run(ctx context.Context, migrations []Migration) ([]Result, error) {
+	if err := validate(migrations); err != nil {
+		return nil, err
+	}
+	if err := r.Manifest.Check(r.Network, r.ChainID); err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, m := range migrations {
+		res, err := r.run(ctx, m)
+		if err != nil {
+			return results, fmt.Errorf("migration %s: %w", m.ID, err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+func (r *Runner) run(ctx context.Context, m Migration) (Result, error) {
+	res := Result{ID: m.ID, Action: m.Action(), Status: StatusApplied}
+	if m.Deploy != nil {
+		res.Name = m.Deploy.Name
+	}
+	rec, ok := r.Manifest.Migration(r.Network, m.ID)
+	if ok && !rec.Pending {
+		res.Status, res.TxHash, res.Block = StatusSkipped, rec.TxHash, rec.Block
+		if m.Deploy == nil {
+			var err error
+			res.Address, err = r.resolve(m.IncBy.Counter)
+			return res, err
+		}
+		d, found := r.Manifest.Deployment(r.Network, m.Deploy.Name)
+		if !found {
+			return res, fmt.Errorf("manifest has no deployment %q on network %s", m.Deploy.Name, r.Network)
+		}
+		res.Address = d.Address
+		// 本地测试链重置后清单中的记录全部失效，提示使用者而不是在新链上跳过
+		if has, err := r.hasCounter(ctx, d.Address); err != nil || !has {
+			return res, cmp.Or(err, fmt.Errorf("%w: %s at %s", ErrStaleManifest, d.Name, d.Address.Hex()))
+		}
+		return res, nil
+	}
+	if ok {
+		dropped, err := r.dropped(ctx, rec)
+		if err != nil {
+			return res, err
+		}
+		ok = !dropped
+	}
+
+	if r.OnStep != nil {
+		r.OnStep(m)
+	}
+	if m.Deploy != nil {
+		return r.deploy(ctx, m, res, rec, ok)
+	}
+	return r.incBy(ctx, m, res, rec, ok)
+}
+
+// deploy 执行部署。pending 为 true 时 rec 记录了上次运行发出、尚未确认的部署交易
+func (r *Runner) deploy(ctx context.Context, m Migration, res Result, rec MigrationRecord, pending bool) (Result, error) {
+	step := m.Deploy
+	code, err := hexutil.Decode(counter.CounterMetaData.Bin)
+	if err != nil {
+		return res, err
+	}
+	d := Deployment{
+		Network:      r.Network,
+		ChainID:      r.ChainID,
+		Name:         step.Name,
+		Deployer:     r.Opts.From,
+		BytecodeHash: BytecodeHash(code),
+	}
+	if c := step.create2; c != nil {
+		d.Factory, d.Salt = &c.Factory, &c.Salt
+	}
+
+	hash := rec.TxHash
+	if !pending {
+		// 之前用 counter deploy -save 部署过同一版本时直接采用
+		if old, ok := r.Manifest.Deployment(r.Network, step.Name); ok && old.BytecodeHash == d.BytecodeHash {
+			has, err := r.hasCounter(ctx, old.Address)
+			if err != nil {
+				return res, err
+			}
+			if has {
+				res.Address, res.TxHash, res.Block = old.Address, old.TxHash, old.Block
+				return res, r.done(m.ID, old.TxHash, old.Block)
+			}
+		}
+		var tx *types.Transaction
+		if c := step.create2; c != nil {
+			if d.Address, tx, err = Deploy2(ctx, r.Opts, r.Backend, *c); err != nil {
+				return res, err
+			}
+			if tx == nil {
+				// 预测地址上已有代码：不是本次部署，只记录地址
+				if has, err := r.hasCounter(ctx, d.Address); err != nil || !has {
+					return res, cmp.Or(err, fmt.Errorf("code at %s is not Counter", d.Address.Hex()))
+				}
+				d.Deployer = common.Address{}
+				r.Manifest.AddDeployment(d)
+				res.Address = d.Address
+				return res, r.done(m.ID, common.Hash{}, 0)
+			}
+		} else if _, tx, _, err = counter.DeployCounter(r.Opts, r.Backend); err != nil {
+			return res, err
+		}
+		hash = tx.Hash()
+		if err := r.pending(m.ID, tx); err != nil {
+			return res, err
+		}
+	}
+
+	receipt, err := r.wait(ctx, m.ID, hash)
+	if err != nil {
+		return res, err
+	}
+	d.TxHash, d.Block = receipt.TxHash, receipt.BlockNumber.Uint64()
+	d.Address = receipt.ContractAddress
+	if c := step.create2; c != nil {
+		d.Address = c.Address()
+	}
+	// CREATE2 工厂失败时交易本身不回滚，需确认预测地址上确实出现了 Counter
+	if has, err := r.hasCounter(ctx, d.Address); err != nil || !has {
+		return res, cmp.Or(err, fmt.Errorf("code at %s is not Counter", d.Address.Hex()))
+	}
+	r.Manifest.AddDeployment(d)
+	res.Address, res.TxHash, res.Block = d.Address, d.TxHash, d.Block
+	return res, r.done(m.ID, d.TxHash, d.Block)
+}
+
+// incBy 调用 IncBy。pending 为 true 时 rec 记录了上次运行发出、尚未确认的交易
+func (r *Runner) incBy(ctx context.Context, m Migration, res Result, rec MigrationRecord, pending bool) (Result, error) {
+	address, err := r.resolve(m.IncBy.Counter)
+	if err != nil {
+		return res, err
+	}
+	res.Address = address
+
+	hash := rec.TxHash
+	if !pending {
+		c, err := counter.NewCounter(address, r.Backend)
+		if err != nil {
+			return res, err
+		}
+		tx, err := c.IncBy(r.Opts, m.IncBy.by)
+		if err != nil {
+			return res, err
+		}
+		hash = tx.Hash()
+		if err := r.pending(m.ID, tx); err != nil {
+			return res, err
+		}
+	}
+	receipt, err := r.wait(ctx, m.ID, hash)
+	if err != nil {
+		return res, err
+	}
+	res.TxHash, res.Block = receipt.TxHash, receipt.BlockNumber.Uint64()
+	return res, r.done(m.ID, res.TxHash, res.Block)
+}
+
+// resolve 把清单中的部署名或十六进制地址解析为地址
+func (r *Runner) resolve(ref string) (common.Address, error) {
+	if d, ok := r.Manifest.Deployment(r.Network, ref); ok {
+		return d.Address, nil
+	}
+	if common.IsHexAddress(ref) {
+		return common.HexToAddress(ref), nil
+	}
+	return common.Address{}, fmt.Errorf("manifest has no deployment %q on network %s", ref, r.Network)
+}
+
+// wait 等待交易确认。交易执行失败时删除 pending 记录，下次运行重新发送
+func (r *Runner) wait(ctx context.Context, id string, hash common.Hash) (*types.Receipt, error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	res, err := r.Waiter.Wait(ctx, hash)
+	var reverted *txmgr.RevertedError
+	if errors.As(err, &reverted) {
+		r.Manifest.DeleteMigration(r.Network, id)
+		if serr := r.Manifest.Save(); serr != nil {
+			return nil, serr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res.Receipt, nil
+}
+
+// pending 记录已发出的交易并立即保存，进程在等待确认时退出也不会丢失
+func (r *Runner) pending(id string, tx *types.Transaction) error {
+	from, nonce := r.Opts.From, tx.Nonce()
+	r.Manifest.SetMigration(MigrationRecord{
+		Network: r.Network, ChainID: r.ChainID, ID: id, TxHash: tx.Hash(), Pending: true,
+		From: &from, Nonce: &nonce,
+	})
+	return r.Manifest.Save()
+}
+
+// dropped 判断 pending 交易是否永远不会上链：没有回执，且发送方已确认的 nonce 越过了它。
+// 先读 nonce 再查回执，nonce 越过时交易如果已经上链，此时一定能查到回执。
+func (r *Runner) dropped(ctx context.Context, rec MigrationRecord) (bool, error) {
+	if r.Chain == nil || rec.From == nil || rec.Nonce == nil {
+		return false, nil
+	}
+	nonce, err := r.Chain.NonceAt(ctx, *rec.From, nil)
+	if err != nil || nonce <= *rec.Nonce {
+		return false, err
+	}
+	_, err = r.Chain.TransactionReceipt(ctx, rec.TxHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return true, nil
+	}
+	return false, err
+}
+
+func (r *Runner) done(id string, hash common.Hash, block uint64) error {
+	r.Manifest.SetMigration(MigrationRecord{Network: r.Network, ChainID: r.ChainID, ID: id, TxHash: hash, Block: block})
+	return r.Manifest.Save()
+}
+
+func (r *Runner) hasCounter(ctx context.Context, address common.Address) (bool, error) {
+	res, err := verify.Verify(ctx, r.Backend, address, counter.CounterMetaData.Bin, nil)
+	if err != nil {
+		return false, err
+	}
+	return res.Status.OK(), nil
+}
@@ -0,0 +1,300 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+
+	"sepolia-block/counter"
+	"sepolia-block/txmgr"
+)
+
+// proxyRuntime 确定性部署代理的运行时代码
+var proxyRuntime = common.FromHex("0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe03601600081602082378035828234f58015156039578182fd5b8082525050506014600cf3")
+
+// commitWaiter 在模拟链上出块后读取回执
+type commitWaiter struct {
+	sim  *simulated.Backend
+	sent int // 等待过的交易数
+}
+
+func (w *commitWaiter) Wait(ctx context.Context, hash common.Hash) (*txmgr.Result, error) {
+	w.sent++
+	w.sim.Commit()
+	receipt, err := w.sim.Client().TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	res := &txmgr.Result{Receipt: receipt, Confirmations: 1}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return res, &txmgr.RevertedError{Receipt: receipt}
+	}
+	return res, nil
+}
+
+func newTestRunner(t *testing.T) (*Runner, *commitWaiter) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		from:               {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))},
+		DeterministicProxy: {Code: proxyRuntime},
+	})
+	t.Cleanup(func() { sim.Close() })
+
+	client := sim.Client()
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := LoadManifest(filepath.Join(t.TempDir(), "deployments.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter := &commitWaiter{sim: sim}
+	return &Runner{
+		Manifest: manifest,
+		Network:  "sim",
+		ChainID:  chainID.Uint64(),
+		Backend:  client,
+		Opts:     auth,
+		Waiter:   waiter,
+		Chain:    client,
+	}, waiter
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{ID: "1-deploy", Deploy: &DeployStep{Name: "default"}},
+		{ID: "2-deploy-create2", Deploy: &DeployStep{Name: "c2", Salt: "counter-v1"}},
+		{ID: "3-seed", IncBy: &IncByStep{Counter: "c2", By: "42"}},
+	}
+}
+
+func TestRunnerIdempotent(t *testing.T) {
+	r, waiter := newTestRunner(t)
+	ctx := context.Background()
+
+	results, err := r.Run(ctx, testMigrations())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(results) != 3 || waiter.sent != 3 {
+		t.Fatalf("%d results, %d transactions, want 3 and 3", len(results), waiter.sent)
+	}
+	for _, res := range results {
+		if res.Status != StatusApplied {
+			t.Errorf("%s: status %s, want %s", res.ID, res.Status, StatusApplied)
+		}
+	}
+	c2 := results[1].Address
+	if want := (Create2{Factory: DeterministicProxy, Salt: crypto.Keccak256Hash([]byte("counter-v1")), InitCode: common.FromHex(counter.CounterMetaData.Bin)}).Address(); c2 != want {
+		t.Errorf("create2 deployment at %s, want %s", c2.Hex(), want.Hex())
+	}
+	if results[2].Address != c2 {
+		t.Errorf("inc_by called %s, want %s", results[2].Address.Hex(), c2.Hex())
+	}
+	x := func() *big.Int {
+		c, err := counter.NewCounter(c2, r.Backend)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := c.X(&bind.CallOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if v := x(); v.Int64() != 42 {
+		t.Fatalf("x = %s after seeding, want 42", v)
+	}
+
+	// 重新加载清单后再次运行：全部跳过，不发送交易
+	r.Manifest, err = LoadManifest(r.Manifest.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Manifest.Deployments) != 2 {
+		t.Fatalf("manifest has %d deployments, want 2", len(r.Manifest.Deployments))
+	}
+	d, ok := r.Manifest.Deployment("sim", "default")
+	if !ok || d.Deployer != r.Opts.From || d.Block == 0 || d.TxHash == (common.Hash{}) || d.Factory != nil {
+		t.Errorf("deployment record %+v", d)
+	}
+	results, err = r.Run(ctx, testMigrations())
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	for _, res := range results {
+		if res.Status != StatusSkipped {
+			t.Errorf("%s: status %s on rerun, want %s", res.ID, res.Status, StatusSkipped)
+		}
+	}
+	if waiter.sent != 3 {
+		t.Errorf("%d transactions after rerun, want 3", waiter.sent)
+	}
+	if v := x(); v.Int64() != 42 {
+		t.Errorf("x = %s after rerun, want 42", v)
+	}
+
+	// 同一网络名指向另一条链时拒绝执行
+	r.ChainID++
+	if _, err := r.Run(ctx, testMigrations()); err == nil || !strings.Contains(err.Error(), "records network sim as chain") {
+		t.Errorf("Run on another chain: %v", err)
+	}
+}
+
+func TestRunnerRevertedStepRetries(t *testing.T) {
+	r, waiter := newTestRunner(t)
+	ctx := context.Background()
+
+	migrations := []Migration{
+		{ID: "1-deploy", Deploy: &DeployStep{Name: "default"}},
+		{ID: "2-seed", IncBy: &IncByStep{Counter: "default", By: "1"}},
+	}
+	if _, err := r.Run(ctx, migrations[:1]); err != nil {
+		t.Fatal(err)
+	}
+	// IncBy 的 gas 固定得过低，交易上链但执行失败
+	r.Opts.GasLimit = 22000
+	_, err := r.Run(ctx, migrations)
+	var reverted *txmgr.RevertedError
+	if !errors.As(err, &reverted) {
+		t.Fatalf("Run: %v, want *txmgr.RevertedError", err)
+	}
+	if _, ok := r.Manifest.Migration("sim", "2-seed"); ok {
+		t.Fatal("reverted migration is still recorded")
+	}
+
+	r.Opts.GasLimit = 0
+	results, err := r.Run(ctx, migrations)
+	if err != nil {
+		t.Fatalf("Run after revert: %v", err)
+	}
+	if results[1].Status != StatusApplied || waiter.sent != 3 {
+		t.Errorf("status %s after %d transactions, want %s after 3", results[1].Status, waiter.sent, StatusApplied)
+	}
+}
+
+func TestRunnerDroppedPendingResent(t *testing.T) {
+	r, waiter := newTestRunner(t)
+	ctx := context.Background()
+
+	migrations := []Migration{
+		{ID: "1-deploy", Deploy: &DeployStep{Name: "default"}},
+		{ID: "2-seed", IncBy: &IncByStep{Counter: "default", By: "7"}},
+	}
+	results, err := r.Run(ctx, migrations[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := counter.NewCounter(results[0].Address, r.Backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := func() int64 {
+		v, err := c.X(&bind.CallOpts{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v.Int64()
+	}
+	// 上次运行发出的 IncBy 从未上链（例如被节点丢弃），清单中仍为 pending
+	dropped := func(nonce uint64) {
+		from := r.Opts.From
+		r.Manifest.SetMigration(MigrationRecord{
+			Network: "sim", ChainID: r.ChainID, ID: "2-seed", TxHash: common.HexToHash("0x01"), Pending: true,
+			From: &from, Nonce: &nonce,
+		})
+	}
+
+	// nonce 还没有被用掉，交易仍可能上链：等待而不是重发
+	dropped(1)
+	if _, err := r.Run(ctx, migrations); !errors.Is(err, ethereum.NotFound) {
+		t.Fatalf("Run with a live pending nonce: %v, want the receipt to be waited for", err)
+	}
+	if v := x(); v != 0 {
+		t.Fatalf("x = %d, want the pending step not to be re-sent", v)
+	}
+
+	// 部署交易已经用掉 nonce 0，记录的交易永远不会上链：重新发送
+	dropped(0)
+	sent := waiter.sent
+	results, err = r.Run(ctx, migrations)
+	if err != nil {
+		t.Fatalf("Run with a dropped pending step: %v", err)
+	}
+	if results[1].Status != StatusApplied || waiter.sent != sent+1 || x() != 7 {
+		t.Errorf("status %s after %d transactions, x = %d, want %s, 1 and 7", results[1].Status, waiter.sent-sent, x(), StatusApplied)
+	}
+	if rec, _ := r.Manifest.Migration("sim", "2-seed"); rec.Pending || rec.TxHash != results[1].TxHash {
+		t.Errorf("manifest record %+v after re-sending", rec)
+	}
+}
+
+func TestRunnerSkippedStepUnresolved(t *testing.T) {
+	r, _ := newTestRunner(t)
+	r.Manifest.SetMigration(MigrationRecord{Network: "sim", ChainID: r.ChainID, ID: "1-seed", TxHash: common.HexToHash("0x01"), Block: 1})
+	_, err := r.Run(context.Background(), []Migration{{ID: "1-seed", IncBy: &IncByStep{Counter: "missing", By: "1"}}})
+	if err == nil || !strings.Contains(err.Error(), `no deployment "missing"`) {
+		t.Errorf("Run: %v, want the unresolved counter to be reported", err)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"ok", "migrations:\n  - id: a\n    deploy: {name: default, salt: x}\n  - id: b\n    inc_by: {counter: default, by: \"100\"}\n", ""},
+		{"no id", "migrations:\n  - deploy: {name: default}\n", "has no id"},
+		{"duplicate id", "migrations:\n  - id: a\n    deploy: {name: x}\n  - id: a\n    deploy: {name: y}\n", "duplicate"},
+		{"two actions", "migrations:\n  - id: a\n    deploy: {name: x}\n    inc_by: {counter: x, by: \"1\"}\n", "exactly one"},
+		{"zero increment", "migrations:\n  - id: a\n    inc_by: {counter: x, by: \"0\"}\n", "positive"},
+		{"factory without salt", "migrations:\n  - id: a\n    deploy: {name: x, factory: \"0x4e59b44847b379578588920cA78FbF26c0B4956C\"}\n", "requires deploy.salt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "migrations.yaml")
+			if err := os.WriteFile(path, []byte(tt.script), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadMigrations(path)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("LoadMigrations: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("LoadMigrations error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManifestVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployments.json")
+	if err := os.WriteFile(path, []byte(`{"version": 2, "deployments": [], "migrations": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadManifest(path); !errors.Is(err, ErrManifestVersion) {
+		t.Errorf("LoadManifest: %v, want ErrManifestVersion", err)
+	}
+}
@@ -0,0 +1,491 @@
+// Package failover 把多个 RPC 节点组合成一个后端：定期检查各节点的区块高度、延迟和错误，
+// 读请求发给最健康的节点，节点不可用时自动换下一个；交易广播到所有节点，任意一个接受即成功。
+//
+// 只有节点本身不可用（连接失败、HTTP 错误、限流）才会切换，节点返回的执行结果
+// （回滚、nonce 过低、未找到等）在其他节点上也一样，直接返回给调用方。
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"sepolia-block/transport"
+)
+
+// ErrNoEndpoint 没有可用（已确认链 ID）的节点
+var ErrNoEndpoint = errors.New("no usable RPC endpoint")
+
+// Health 节点健康状态快照
+type Health struct {
+	Endpoint string        // 节点名（协议和主机，不含可能带密钥的路径）
+	Head     uint64        // 最近一次看到的区块高度
+	Lag      uint64        // 落后于所有节点最高区块的数量
+	Latency  time.Duration // 成功请求耗时的滑动平均
+	Failures int           // 连续失败次数
+	Err      error         // 最近一次错误
+	Healthy  bool
+}
+
+// endpoint 单个节点及其健康状态
+type endpoint struct {
+	name   string
+	client *ethclient.Client
+
+	mu       sync.Mutex
+	verified bool // 链 ID 已确认与其他节点一致
+	head     uint64
+	latency  time.Duration
+	failures int
+	err      error
+}
+
+// observe 记录一次请求的结果：成功时更新延迟并清零失败计数，节点不可用时累加失败次数
+func (e *endpoint) observe(elapsed time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err == nil {
+		if e.latency == 0 {
+			e.latency = elapsed
+		} else {
+			e.latency = (e.latency*7 + elapsed*3) / 10
+		}
+		e.failures, e.err = 0, nil
+		return
+	}
+	if unavailable(err) {
+		e.failures++
+		e.err = err
+	}
+}
+
+func (e *endpoint) setHead(head uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if head > e.head {
+		e.head = head
+	}
+}
+
+// Backend 多节点后端，满足 bind.ContractBackend 以及 chain.Backend
+type Backend struct {
+	endpoints []*endpoint
+	chainID   *big.Int
+
+	MaxLag       uint64        // 落后最高区块超过该数量视为不健康，默认 3
+	CheckTimeout time.Duration // 单个节点健康检查的超时，默认 5s
+	// OnFailover 可选，某个节点不可用、请求改发其他节点时回调
+	OnFailover func(endpoint string, err error)
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// DialFunc 连接单个节点
+type DialFunc func(ctx context.Context, url string) (*ethclient.Client, error)
+
+// Dial 连接所有节点并做一次健康检查。chainID 为 0 时以第一个应答的节点（按 urls 顺序）为准，
+// 链 ID 与之不同的节点不会被使用；连接时不可用的节点在之后的健康检查中恢复后加入。
+// dial 为 nil 时使用 ethclient.DialContext。
+func Dial(ctx context.Context, urls []string, chainID uint64, dial DialFunc) (*Backend, error) {
+	if dial == nil {
+		dial = ethclient.DialContext
+	}
+	b := &Backend{MaxLag: 3, CheckTimeout: 5 * time.Second, stop: make(chan struct{})}
+	if chainID != 0 {
+		b.chainID = new(big.Int).SetUint64(chainID)
+	}
+	var errs []error
+	for _, u := range urls {
+		c, err := dial(ctx, u)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", endpointName(u), err))
+			continue
+		}
+		b.endpoints = append(b.endpoints, &endpoint{name: endpointName(u), client: c})
+	}
+	if b.chainID == nil {
+		// 按 urls 顺序取第一个应答的节点的链 ID 为准
+		for _, e := range b.endpoints {
+			cctx, cancel := context.WithTimeout(ctx, b.CheckTimeout)
+			id, err := e.client.ChainID(cctx)
+			cancel()
+			if err == nil {
+				b.chainID = id
+				break
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+		}
+	}
+	if b.chainID != nil {
+		b.Check(ctx)
+	}
+	if len(b.usable()) == 0 {
+		for _, e := range b.endpoints {
+			if e.err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", e.name, e.err))
+			}
+		}
+		b.Close()
+		return nil, fmt.Errorf("%w: %w", ErrNoEndpoint, errors.Join(errs...))
+	}
+	return b, nil
+}
+
+// endpointName 节点名只保留协议和主机，避免把 URL 路径中的 API key 写进日志
+func endpointName(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Start 每隔 interval 在后台做一次健康检查，直到 Close
+func (b *Backend) Start(interval time.Duration) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stop:
+				return
+			case <-ticker.C:
+				b.Check(context.Background())
+			}
+		}
+	}()
+}
+
+// Close 停止后台检查并关闭所有连接
+func (b *Backend) Close() {
+	select {
+	case <-b.stop:
+	default:
+		close(b.stop)
+	}
+	b.wg.Wait()
+	for _, e := range b.endpoints {
+		e.client.Close()
+	}
+}
+
+// Check 并发查询所有节点的区块高度，更新延迟和错误；尚未确认链 ID 的节点先确认链 ID
+func (b *Backend) Check(ctx context.Context) []Health {
+	var wg sync.WaitGroup
+	for _, e := range b.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, b.CheckTimeout)
+			defer cancel()
+			if err := b.verify(ctx, e); err != nil {
+				return
+			}
+			start := time.Now()
+			head, err := e.client.BlockNumber(ctx)
+			e.observe(time.Since(start), err)
+			if err == nil {
+				e.setHead(head)
+			}
+		}()
+	}
+	wg.Wait()
+	return b.Health()
+}
+
+// verify 确认节点的链 ID 与后端一致，已确认的节点不再查询
+func (b *Backend) verify(ctx context.Context, e *endpoint) error {
+	e.mu.Lock()
+	verified := e.verified
+	e.mu.Unlock()
+	if verified {
+		return nil
+	}
+	id, err := e.client.ChainID(ctx)
+	if err == nil && id.Cmp(b.chainID) != 0 {
+		err = fmt.Errorf("chain ID %s, expected %s", id, b.chainID)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		e.failures++
+		e.err = err
+		return err
+	}
+	e.verified = true
+	return nil
+}
+
+// Health 返回各节点的健康状态，按优先使用的顺序排列
+func (b *Backend) Health() []Health {
+	eps := b.ordered()
+	out := make([]Health, len(eps))
+	for i, s := range eps {
+		out[i] = s.Health
+	}
+	return out
+}
+
+type snapshot struct {
+	Health
+	e *endpoint
+}
+
+// ordered 按健康程度排序的节点：健康的在前，其中延迟低的优先；
+// 不健康的仍排在最后作为兜底，按失败次数和落后区块数排序。未确认链 ID 的节点不参与。
+func (b *Backend) ordered() []snapshot {
+	var (
+		eps []snapshot
+		top uint64
+	)
+	for _, e := range b.endpoints {
+		e.mu.Lock()
+		s := snapshot{Health: Health{Endpoint: e.name, Head: e.head, Latency: e.latency, Failures: e.failures, Err: e.err}, e: e}
+		verified := e.verified
+		e.mu.Unlock()
+		if !verified {
+			continue
+		}
+		top = max(top, s.Head)
+		eps = append(eps, s)
+	}
+	for i := range eps {
+		eps[i].Lag = top - eps[i].Head
+		eps[i].Healthy = eps[i].Failures == 0 && eps[i].Lag <= b.MaxLag
+	}
+	sort.SliceStable(eps, func(i, j int) bool {
+		a, c := eps[i].Health, eps[j].Health
+		switch {
+		case a.Healthy != c.Healthy:
+			return a.Healthy
+		case !a.Healthy && a.Failures != c.Failures:
+			return a.Failures < c.Failures
+		case !a.Healthy && a.Lag != c.Lag:
+			return a.Lag < c.Lag
+		}
+		return a.Latency < c.Latency
+	})
+	return eps
+}
+
+// usable 可以发送请求的节点
+func (b *Backend) usable() []*endpoint {
+	eps := b.ordered()
+	out := make([]*endpoint, len(eps))
+	for i, s := range eps {
+		out[i] = s.e
+	}
+	return out
+}
+
+// unavailable 判断错误是否说明节点本身不可用，应换一个节点重试。
+// 节点按 JSON-RPC 返回的错误（限流除外）和 NotFound 是请求本身的结果，不切换；
+// 同样使用 -32005 的结果数量或区块范围超限也是请求本身的问题。
+func unavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.ErrorCode() == -32005 && !transport.ResultLimit(rpcErr.Error()) // limit exceeded
+	}
+	return true
+}
+
+// call 按健康顺序在节点上执行 fn，节点不可用时换下一个
+func call[T any](ctx context.Context, b *Backend, fn func(*ethclient.Client) (T, error)) (T, error) {
+	var (
+		zero T
+		errs []error
+	)
+	eps := b.usable()
+	for i, e := range eps {
+		start := time.Now()
+		v, err := fn(e.client)
+		if errors.Is(err, errSkip) {
+			continue
+		}
+		e.observe(time.Since(start), err)
+		if err == nil || !unavailable(err) || ctx.Err() != nil {
+			return v, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+		if b.OnFailover != nil && i+1 < len(eps) {
+			b.OnFailover(e.name, err)
+		}
+	}
+	if len(errs) == 0 {
+		return zero, ErrNoEndpoint
+	}
+	return zero, fmt.Errorf("all %d endpoints failed: %w", len(eps), errors.Join(errs...))
+}
+
+// SendTransaction 把交易并发广播到所有节点，任意一个节点接受（或已经有这笔交易）即成功。
+// 全部拒绝时优先返回节点给出的错误（如 nonce too low），而不是连接错误。
+func (b *Backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	eps := b.usable()
+	if len(eps) == 0 {
+		return ErrNoEndpoint
+	}
+	errs := make([]error, len(eps))
+	var wg sync.WaitGroup
+	for i, e := range eps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := e.client.SendTransaction(ctx, tx)
+			e.observe(time.Since(start), err)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", e.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err == nil || alreadyKnown(err) {
+			return nil
+		}
+	}
+	for _, err := range errs {
+		if !unavailable(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("all %d endpoints failed: %w", len(eps), errors.Join(errs...))
+}
+
+func alreadyKnown(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
+
+// ChainID 返回连接时确认的链 ID
+func (b *Backend) ChainID(context.Context) (*big.Int, error) {
+	return new(big.Int).Set(b.chainID), nil
+}
+
+func (b *Backend) BlockNumber(ctx context.Context) (uint64, error) {
+	return call(ctx, b, func(c *ethclient.Client) (uint64, error) { return c.BlockNumber(ctx) })
+}
+
+func (b *Backend) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return call(ctx, b, func(c *ethclient.Client) (*types.Block, error) { return c.BlockByNumber(ctx, number) })
+}
+
+func (b *Backend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return call(ctx, b, func(c *ethclient.Client) (*types.Block, error) { return c.BlockByHash(ctx, hash) })
+}
+
+func (b *Backend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return call(ctx, b, func(c *ethclient.Client) (*types.Header, error) { return c.HeaderByNumber(ctx, number) })
+}
+
+func (b *Backend) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx      *types.Transaction
+		pending bool
+	}
+	res, err := call(ctx, b, func(c *ethclient.Client) (result, error) {
+		tx, pending, err := c.TransactionByHash(ctx, hash)
+		return result{tx, pending}, err
+	})
+	return res.tx, res.pending, err
+}
+
+func (b *Backend) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	return call(ctx, b, func(c *ethclient.Client) (*types.Receipt, error) { return c.TransactionReceipt(ctx, hash) })
+}
+
+func (b *Backend) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, b, func(c *ethclient.Client) ([]byte, error) { return c.CodeAt(ctx, account, blockNumber) })
+}
+
+func (b *Backend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return call(ctx, b, func(c *ethclient.Client) ([]byte, error) { return c.PendingCodeAt(ctx, account) })
+}
+
+func (b *Backend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return call(ctx, b, func(c *ethclient.Client) (uint64, error) { return c.NonceAt(ctx, account, blockNumber) })
+}
+
+func (b *Backend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return call(ctx, b, func(c *ethclient.Client) (uint64, error) { return c.PendingNonceAt(ctx, account) })
+}
+
+func (b *Backend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, b, func(c *ethclient.Client) ([]byte, error) { return c.CallContract(ctx, msg, blockNumber) })
+}
+
+func (b *Backend) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return call(ctx, b, func(c *ethclient.Client) ([]byte, error) { return c.PendingCallContract(ctx, msg) })
+}
+
+func (b *Backend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return call(ctx, b, func(c *ethclient.Client) (uint64, error) { return c.EstimateGas(ctx, msg) })
+}
+
+func (b *Backend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return call(ctx, b, func(c *ethclient.Client) (*big.Int, error) { return c.SuggestGasPrice(ctx) })
+}
+
+func (b *Backend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return call(ctx, b, func(c *ethclient.Client) (*big.Int, error) { return c.SuggestGasTipCap(ctx) })
+}
+
+func (b *Backend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return call(ctx, b, func(c *ethclient.Client) (*ethereum.FeeHistory, error) {
+		return c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+func (b *Backend) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return call(ctx, b, func(c *ethclient.Client) ([]types.Log, error) { return c.FilterLogs(ctx, q) })
+}
+
+// SubscribeFilterLogs 在最健康的支持订阅的节点上订阅。订阅建立后不再切换，
+// 连接中断时由调用方（如 indexer.Follower）重新订阅，届时会选择当前最健康的节点。
+func (b *Backend) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return subscribe(ctx, b, func(c *ethclient.Client) (ethereum.Subscription, error) { return c.SubscribeFilterLogs(ctx, q, ch) })
+}
+
+func (b *Backend) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return subscribe(ctx, b, func(c *ethclient.Client) (ethereum.Subscription, error) { return c.SubscribeNewHead(ctx, ch) })
+}
+
+// subscribe 与 call 相同，但跳过不支持订阅的（HTTP）节点，它们不算失败；
+// 所有节点都不支持时返回 rpc.ErrNotificationsUnsupported，调用方据此改为轮询
+func subscribe(ctx context.Context, b *Backend, fn func(*ethclient.Client) (ethereum.Subscription, error)) (ethereum.Subscription, error) {
+	unsupported := true
+	sub, err := call(ctx, b, func(c *ethclient.Client) (ethereum.Subscription, error) {
+		sub, err := fn(c)
+		if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+			return nil, errSkip
+		}
+		unsupported = false
+		return sub, err
+	})
+	if err != nil && unsupported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	return sub, err
+}
+
+// errSkip 节点不支持该请求，换下一个节点但不计入失败
+var errSkip = errors.New("not supported by endpoint")
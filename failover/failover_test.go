@@ -0,0 +1,299 @@
+package failover
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// stub 最小的 JSON-RPC 节点，可为每个方法设置 HTTP 错误或 JSON-RPC 错误
+type stub struct {
+	*httptest.Server
+	chainID uint64
+	delay   time.Duration
+
+	mu       sync.Mutex
+	head     uint64
+	calls    map[string]int
+	status   map[string]int    // 方法 → HTTP 状态码
+	rpcError map[string]string // 方法 → JSON-RPC 错误消息
+	rpcCode  map[string]int    // 方法 → JSON-RPC 错误码，默认 -32000
+}
+
+func newStub(t *testing.T, chainID, head uint64, delay time.Duration) *stub {
+	t.Helper()
+	s := &stub{
+		chainID:  chainID,
+		delay:    delay,
+		head:     head,
+		calls:    make(map[string]int),
+		status:   make(map[string]int),
+		rpcError: make(map[string]string),
+		rpcCode:  make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stub) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	time.Sleep(s.delay)
+
+	s.mu.Lock()
+	s.calls[req.Method]++
+	status, msg, code, head := s.status[req.Method], s.rpcError[req.Method], s.rpcCode[req.Method], s.head
+	s.mu.Unlock()
+
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+	switch {
+	case msg != "":
+		resp["error"] = map[string]any{"code": cmp.Or(code, -32000), "message": msg}
+	case req.Method == "eth_chainId":
+		resp["result"] = hexutil.EncodeUint64(s.chainID)
+	case req.Method == "eth_blockNumber":
+		resp["result"] = hexutil.EncodeUint64(head)
+	case req.Method == "eth_getLogs":
+		resp["result"] = []any{}
+	case req.Method == "eth_sendRawTransaction":
+		resp["result"] = common.Hash{}.Hex()
+	default:
+		resp["result"] = "0x6001"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *stub) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+func (s *stub) set(method string, status int, rpcError string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[method], s.rpcError[method] = status, rpcError
+}
+
+// setCode 让 method 返回指定错误码的 JSON-RPC 错误
+func (s *stub) setCode(method string, code int, rpcError string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rpcCode[method], s.rpcError[method] = code, rpcError
+}
+
+func dial(t *testing.T, chainID uint64, stubs ...*stub) *Backend {
+	t.Helper()
+	urls := make([]string, len(stubs))
+	for i, s := range stubs {
+		urls[i] = s.URL
+	}
+	b, err := Dial(context.Background(), urls, chainID, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(b.Close)
+	return b
+}
+
+func TestReadsGoToHealthiest(t *testing.T) {
+	slow := newStub(t, 1337, 100, 30*time.Millisecond)
+	fast := newStub(t, 1337, 100, 0)
+	lagging := newStub(t, 1337, 90, 0)
+	b := dial(t, 1337, slow, lagging, fast)
+
+	health := b.Health()
+	if len(health) != 3 || health[0].Endpoint != endpointName(fast.URL) || health[2].Endpoint != endpointName(lagging.URL) {
+		t.Fatalf("health order %+v, want fast, slow, lagging", health)
+	}
+	if health[2].Healthy || health[2].Lag != 10 {
+		t.Errorf("lagging endpoint %+v, want unhealthy with lag 10", health[2])
+	}
+	if _, err := b.CodeAt(context.Background(), common.Address{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if fast.count("eth_getCode") != 1 || slow.count("eth_getCode") != 0 || lagging.count("eth_getCode") != 0 {
+		t.Errorf("eth_getCode calls fast=%d slow=%d lagging=%d, want 1/0/0",
+			fast.count("eth_getCode"), slow.count("eth_getCode"), lagging.count("eth_getCode"))
+	}
+}
+
+func TestFailover(t *testing.T) {
+	primary := newStub(t, 1337, 100, 0)
+	backup := newStub(t, 1337, 100, 20*time.Millisecond)
+	b := dial(t, 1337, primary, backup)
+	var failedOver []string
+	b.OnFailover = func(endpoint string, err error) { failedOver = append(failedOver, endpoint) }
+
+	primary.set("eth_getCode", http.StatusServiceUnavailable, "")
+	code, err := b.CodeAt(context.Background(), common.Address{}, nil)
+	if err != nil || len(code) == 0 {
+		t.Fatalf("CodeAt = %x, %v", code, err)
+	}
+	if primary.count("eth_getCode") != 1 || backup.count("eth_getCode") != 1 {
+		t.Errorf("eth_getCode calls primary=%d backup=%d, want 1/1", primary.count("eth_getCode"), backup.count("eth_getCode"))
+	}
+	if len(failedOver) != 1 || failedOver[0] != endpointName(primary.URL) {
+		t.Errorf("OnFailover called for %v", failedOver)
+	}
+	// 失败的节点排到后面，之后的请求直接发给备用节点
+	if health := b.Health(); health[0].Endpoint != endpointName(backup.URL) || health[1].Healthy {
+		t.Errorf("health after failure %+v", health)
+	}
+	if _, err := b.CodeAt(context.Background(), common.Address{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if primary.count("eth_getCode") != 1 {
+		t.Errorf("unhealthy primary received %d eth_getCode calls, want 1", primary.count("eth_getCode"))
+	}
+
+	// 健康检查成功后恢复
+	primary.set("eth_getCode", 0, "")
+	if health := b.Check(context.Background()); health[0].Endpoint != endpointName(primary.URL) || !health[0].Healthy {
+		t.Errorf("health after recovery %+v", health)
+	}
+
+	// 所有节点都不可用时返回汇总的错误
+	primary.set("eth_getCode", http.StatusBadGateway, "")
+	backup.set("eth_getCode", http.StatusBadGateway, "")
+	if _, err := b.CodeAt(context.Background(), common.Address{}, nil); err == nil || !strings.Contains(err.Error(), "all 2 endpoints failed") {
+		t.Errorf("CodeAt with all endpoints down: %v", err)
+	}
+}
+
+func TestNodeErrorNotRetried(t *testing.T) {
+	primary := newStub(t, 1337, 100, 0)
+	backup := newStub(t, 1337, 100, 20*time.Millisecond)
+	b := dial(t, 1337, primary, backup)
+
+	primary.set("eth_call", 0, "execution reverted")
+	_, err := b.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("CallContract: %v, want the node's JSON-RPC error", err)
+	}
+	if backup.count("eth_call") != 0 {
+		t.Error("deterministic error was retried on another endpoint")
+	}
+	if health := b.Health(); !health[0].Healthy {
+		t.Errorf("endpoint marked unhealthy by a node error: %+v", health[0])
+	}
+}
+
+func TestResultLimitNotFailedOver(t *testing.T) {
+	primary := newStub(t, 1337, 100, 0)
+	backup := newStub(t, 1337, 100, 20*time.Millisecond)
+	b := dial(t, 1337, primary, backup)
+
+	// Infura 对 eth_getLogs 结果过多也返回 -32005，这是查询本身的问题，不是节点不可用
+	const msg = "query returned more than 10000 results"
+	primary.setCode("eth_getLogs", -32005, msg)
+	_, err := b.FilterLogs(context.Background(), ethereum.FilterQuery{})
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32005 || rpcErr.Error() != msg {
+		t.Fatalf("FilterLogs: %v, want the node's -32005 error unchanged", err)
+	}
+	if backup.count("eth_getLogs") != 0 {
+		t.Error("too-many-results error was retried on another endpoint")
+	}
+	if health := b.Health(); health[0].Endpoint != endpointName(primary.URL) || !health[0].Healthy {
+		t.Errorf("endpoint marked unhealthy by a too-many-results error: %+v", health)
+	}
+
+	// 限流仍然切换节点
+	primary.setCode("eth_getLogs", -32005, "limit exceeded")
+	if _, err := b.FilterLogs(context.Background(), ethereum.FilterQuery{}); err != nil {
+		t.Fatalf("FilterLogs with the primary rate limited: %v", err)
+	}
+	if backup.count("eth_getLogs") != 1 {
+		t.Errorf("backup received %d eth_getLogs calls, want 1", backup.count("eth_getLogs"))
+	}
+}
+
+func TestSendBroadcast(t *testing.T) {
+	a := newStub(t, 1337, 100, 0)
+	c := newStub(t, 1337, 100, 0)
+	b := dial(t, 1337, a, c)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1337)), &types.DynamicFeeTx{
+		ChainID: big.NewInt(1337), Gas: 21000, GasFeeCap: big.NewInt(1), GasTipCap: big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// 一个节点已经有这笔交易也算成功
+	c.set("eth_sendRawTransaction", 0, "already known")
+	if err := b.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+	if a.count("eth_sendRawTransaction") != 1 || c.count("eth_sendRawTransaction") != 1 {
+		t.Errorf("eth_sendRawTransaction calls %d/%d, want 1/1", a.count("eth_sendRawTransaction"), c.count("eth_sendRawTransaction"))
+	}
+
+	// 一个节点不可用、另一个拒绝时返回节点的拒绝原因
+	a.set("eth_sendRawTransaction", http.StatusBadGateway, "")
+	c.set("eth_sendRawTransaction", 0, "nonce too low")
+	if err := b.SendTransaction(ctx, tx); err == nil || !strings.Contains(err.Error(), "nonce too low") {
+		t.Errorf("SendTransaction: %v, want nonce too low", err)
+	}
+}
+
+func TestChainIDMismatch(t *testing.T) {
+	right := newStub(t, 1337, 100, 0)
+	wrong := newStub(t, 1, 200, 0)
+	b := dial(t, 0, right, wrong)
+
+	if id, _ := b.ChainID(context.Background()); id.Uint64() != 1337 {
+		t.Errorf("chain ID %s, want 1337 from the first endpoint", id)
+	}
+	if health := b.Health(); len(health) != 1 || health[0].Endpoint != endpointName(right.URL) || !health[0].Healthy {
+		t.Errorf("health %+v, want only the endpoint on chain 1337", health)
+	}
+	if _, err := Dial(context.Background(), []string{wrong.URL}, 1337, nil); !errors.Is(err, ErrNoEndpoint) {
+		t.Errorf("Dial with only the wrong chain: %v, want ErrNoEndpoint", err)
+	}
+}
+
+func TestSubscribeOverHTTP(t *testing.T) {
+	b := dial(t, 1337, newStub(t, 1337, 100, 0), newStub(t, 1337, 100, 0))
+	_, err := b.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, make(chan types.Log))
+	if !errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		t.Errorf("SubscribeFilterLogs over HTTP: %v, want rpc.ErrNotificationsUnsupported", err)
+	}
+	if health := b.Health(); !health[0].Healthy || !health[1].Healthy {
+		t.Errorf("HTTP endpoints marked unhealthy by a subscription: %+v", health)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"sepolia-block/cassette"
+	"sepolia-block/chain"
+	"sepolia-block/config"
+	"sepolia-block/failover"
+	"sepolia-block/i18n"
+	"sepolia-block/output"
+	"sepolia-block/signer"
+	"sepolia-block/transport"
+)
+
+// globalFlags 所有子命令共享的参数，未设置的参数由配置文件补全
+type globalFlags struct {
+	configPath string
+	network    string
+	rpc        string
+	chainID    uint64
+	key        string
+	output     string
+	lang       string
+	rpcTimeout time.Duration
+	rateLimit  float64
+	record     string
+	replay     string
+	tx         txFlags
+
+	cfg     *config.Config
+	profile config.Network
+}
+
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.configPath, "config", "", msgs.Sprintf(i18n.FlagConfig, config.DefaultPath))
+	fs.StringVar(&g.network, "network", "", msgs.Sprintf(i18n.FlagNetwork))
+	fs.StringVar(&g.rpc, "rpc", "", msgs.Sprintf(i18n.FlagRPC))
+	fs.Uint64Var(&g.chainID, "chain-id", 0, msgs.Sprintf(i18n.FlagChainID))
+	fs.StringVar(&g.key, "key", "", msgs.Sprintf(i18n.FlagKey))
+	fs.StringVar(&g.output, "output", "", msgs.Sprintf(i18n.FlagOutput))
+	fs.StringVar(&g.lang, "lang", "", msgs.Sprintf(i18n.FlagLang))
+	fs.DurationVar(&g.rpcTimeout, "rpc-timeout", 30*time.Second, msgs.Sprintf(i18n.FlagRPCTimeout))
+	fs.Float64Var(&g.rateLimit, "rate-limit", 0, msgs.Sprintf(i18n.FlagRateLimit))
+	fs.StringVar(&g.record, "record", "", msgs.Sprintf(i18n.FlagRecord))
+	fs.StringVar(&g.replay, "replay", "", msgs.Sprintf(i18n.FlagReplay))
+}
+
+// parse 解析参数并按 配置文件 → 环境变量 → 命令行 的顺序确定最终配置
+func (g *globalFlags) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// 回放时默认不读写 nonce 文件：其中是真实网络上的在途记录，会让签出的交易与录制时不同
+	if g.replay != "" && !isSet(fs, "nonce-file") {
+		g.tx.nonceFile = ""
+	}
+	cfg, err := config.Load(g.configPath)
+	if err != nil {
+		return err
+	}
+	profile, err := cfg.Profile(g.network)
+	if err != nil {
+		return err
+	}
+	g.cfg, g.profile = cfg, profile
+	if g.rpc == "" {
+		g.rpc = profile.RPC
+	}
+	if g.chainID == 0 {
+		g.chainID = profile.ChainID
+	}
+	if g.rateLimit == 0 {
+		g.rateLimit = profile.RateLimit
+	}
+	if g.key == "" {
+		g.key = cfg.Key
+	}
+	if g.output == "" {
+		g.output = cfg.Output
+	}
+	return g.validate()
+}
+
+// isSet 命令行上是否显式指定了参数 name
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// newFlagSet 创建带公共参数的 FlagSet
+func newFlagSet(name string, g *globalFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	g.register(fs)
+	return fs
+}
+
+// parseArg 与 parse 相同，另外取出一个可选的位置参数，
+// 它既可以写在 flag 之前（block 123 -output json）也可以写在之后
+func (g *globalFlags) parseArg(fs *flag.FlagSet, args []string) (string, error) {
+	var arg string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		arg, args = args[0], args[1:]
+	}
+	if err := g.parse(fs, args); err != nil {
+		return "", err
+	}
+	switch {
+	case fs.NArg() > 1 || (arg != "" && fs.NArg() > 0):
+		return "", msgs.Errorf(i18n.ErrUnexpectedArgs, fs.Args())
+	case arg == "" && fs.NArg() == 1:
+		arg = fs.Arg(0)
+	}
+	return arg, nil
+}
+
+func (g *globalFlags) validate() error {
+	if g.rpc == "" {
+		return msgs.Errorf(i18n.ErrNoRPC, g.profile.Name)
+	}
+	if g.chainID == 0 {
+		return msgs.Errorf(i18n.ErrNoChainID, g.profile.Name)
+	}
+	if g.record != "" && g.replay != "" {
+		return msgs.Errorf(i18n.ErrRecordReplay)
+	}
+	_, err := output.ParseFormat(g.output)
+	return err
+}
+
+// detectLang 在创建 FlagSet 之前确定消息语言，参数说明也需要按语言生成。
+// -lang 写在子命令之后，这里直接在参数中查找，未指定时按 LANG 等环境变量选择。
+func detectLang(args []string) (i18n.Lang, error) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "lang" {
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				break
+			}
+			value = args[i+1]
+		}
+		return i18n.Parse(value)
+	}
+	return i18n.FromEnv(), nil
+}
+
+// dial 连接节点，并确认节点的链 ID 与网络配置一致；-rpc 为逗号分隔的多个节点时切换节点会打印提示。
+// HTTP 节点的限流和暂时性错误按退避重试，每次调用不超过 -rpc-timeout。
+// -record 把调用录制到 cassette，-replay 从 cassette 回放而不连接节点。
+func (g *globalFlags) dial() (*chain.Client, error) {
+	ctx := context.Background()
+	if g.replay != "" {
+		rc, err := cassette.Dial(ctx, g.replay)
+		if err != nil {
+			return nil, msgs.Errorf(i18n.ErrConnect, err)
+		}
+		client, err := chain.New(ctx, ethclient.NewClient(rc), g.chainID)
+		if err != nil {
+			rc.Close()
+			return nil, msgs.Errorf(i18n.ErrConnect, err)
+		}
+		return client, nil
+	}
+
+	opts := transport.Options{
+		CallTimeout: g.rpcTimeout,
+		Rate:        g.rateLimit,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressRetry, err, delay.Round(time.Millisecond), attempt))
+		},
+	}
+	if g.record != "" {
+		// 每条调用立即写入文件，命令结束后由 run 关闭
+		rec, err := cassette.NewRecorder(g.record)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, rec)
+		opts.Wrap = rec.Wrap
+	}
+	client, err := chain.Dial(ctx, g.rpc, g.chainID, opts)
+	if err != nil {
+		return nil, msgs.Errorf(i18n.ErrConnect, err)
+	}
+	if fb, ok := client.Backend.(*failover.Backend); ok {
+		fb.OnFailover = func(endpoint string, err error) {
+			fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressFailover, endpoint, err))
+		}
+	}
+	return client, nil
+}
+
+// signer 按 -key 指定的来源打开签名器，keystore 密码和助记词口令只从环境变量读取。
+// 返回的签名器只能为 client 确认过的链签名。
+func (g *globalFlags) signer(ctx context.Context, client *chain.Client) (signer.Signer, error) {
+	s, err := signer.Open(ctx, g.key, signer.Options{
+		Password:   os.Getenv(config.EnvPrefix + "KEYSTORE_PASSWORD"),
+		Passphrase: os.Getenv(config.EnvPrefix + "MNEMONIC_PASSPHRASE"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.Guard(s), nil
+}
+
+// transactOpts 生成合约调用的交易参数，并填入按策略估算的手续费
+func (g *globalFlags) transactOpts(ctx context.Context, client *chain.Client) (*bind.TransactOpts, error) {
+	s, err := g.signer(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := s.TransactOpts(ctx, client.ID())
+	if err != nil {
+		return nil, err
+	}
+	fees, err := g.fees(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	fees.Apply(opts)
+	opts.GasLimit = g.tx.gasLimit
+	return opts, nil
+}
+
+// writer 按 -output 指定的格式输出到标准输出
+func (g *globalFlags) writer() *output.Writer {
+	return output.NewWriter(os.Stdout, output.Format(g.output))
+}
+
+// print 输出单条结果
+func (g *globalFlags) print(rec output.Record) error {
+	return g.writer().Write(rec)
+}
+
+// printList 输出一组结果
+func (g *globalFlags) printList(recs []output.Record) error {
+	return g.writer().WriteList(recs)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sepolia-block/i18n"
+)
+
+func TestReplayDisablesNonceFile(t *testing.T) {
+	msgs = i18n.NewPrinter(i18n.EN)
+	config := filepath.Join(t.TempDir(), "sepolia-block.yaml")
+	if err := os.WriteFile(config, []byte("network: sepolia\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	nonces := filepath.Join(t.TempDir(), "nonces.json")
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"default", nil, defaultNonceFile()},
+		{"replay", []string{"-replay", "session.ndjson"}, ""},
+		{"replay with explicit file", []string{"-replay", "session.ndjson", "-nonce-file", nonces}, nonces},
+		{"record", []string{"-record", "session.ndjson"}, defaultNonceFile()},
+	}
+	for _, tt := range tests {
+		var g globalFlags
+		fs := newFlagSet("counter inc", &g)
+		g.tx.register(fs)
+		args := append([]string{"-config", config, "-rpc", "http://127.0.0.1:8545", "-chain-id", "31337"}, tt.args...)
+		if err := g.parse(fs, args); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if g.tx.nonceFile != tt.want {
+			t.Errorf("%s: nonce file %q, want %q", tt.name, g.tx.nonceFile, tt.want)
+		}
+	}
+}
+
+// closeFunc 记录关闭顺序的 io.Closer
+type closeFunc func() error
+
+func (f closeFunc) Close() error { return f() }
+
+func TestRunClosesResources(t *testing.T) {
+	var order []string
+	errClose := errors.New("close failed")
+	commands["test-open"] = command{run: func([]string) error {
+		closers = append(closers,
+			closeFunc(func() error { order = append(order, "first"); return nil }),
+			closeFunc(func() error { order = append(order, "second"); return errClose }))
+		return nil
+	}}
+	defer delete(commands, "test-open")
+
+	if err := run([]string{"test-open"}); !errors.Is(err, errClose) {
+		t.Errorf("run: %v, want the close error", err)
+	}
+	if len(order) != 2 || order[0] != "second" || order[1] != "first" {
+		t.Errorf("closed %v, want [second first]", order)
+	}
+	if len(closers) != 0 {
+		t.Errorf("%d closers left after run", len(closers))
+	}
+}
@@ -0,0 +1,170 @@
+package i18n
+
+var en = map[Key]string{
+	UsageHeader:   "Usage: %s <command> [flags]",
+	UsageCommands: "Commands:",
+
+	CmdBlock:          "Show block information",
+	CmdTransfer:       "Send an ETH transfer",
+	CmdCounter:        "Counter contract operations (deploy/inc/inc-by/get/events/index/follow/audit/verify/migrate)",
+	CmdTx:             "Handle stuck transactions (speedup/cancel)",
+	CmdCounterDeploy:  "Deploy a new Counter contract",
+	CmdCounterInc:     "Call inc()",
+	CmdCounterIncBy:   "Call incBy(by)",
+	CmdCounterGet:     "Read the current count x",
+	CmdCounterEvents:  "List Increment events",
+	CmdCounterIndex:   "Backfill Increment events into the local index",
+	CmdCounterFollow:  "Follow Increment events live into the local index",
+	CmdCounterAudit:   "Check x against the sum of Increment events per block range",
+	CmdCounterVerify:  "Check that the code at an address is the Counter contract",
+	CmdCounterMigrate: "Run deployment migrations and record the results in the manifest",
+	CmdTxSpeedup:      "Resend a transaction with the same nonce and higher fees",
+	CmdTxCancel:       "Cancel a transaction with a zero-value self-transfer at the same nonce",
+
+	FlagConfig:     "config file path (default $SEPOLIA_BLOCK_CONFIG or ./%s)",
+	FlagNetwork:    "network profile, e.g. sepolia, anvil, mainnet-fork",
+	FlagRPC:        "RPC endpoint (overrides the network profile); separate several with commas for failover",
+	FlagChainID:    "chain ID (overrides the network profile)",
+	FlagKey:        "key source: env:<var>, hex:<key>, keystore:<file>, mnemonic:<var>[#path], clef:<URL>[#address], remote:<URL>[#address]",
+	FlagOutput:     "output format: text, json, ndjson or csv",
+	FlagLang:       "message language: zh or en (default from the LANG environment variable)",
+	FlagRPCTimeout: "timeout for a single RPC call including retries (0 means no limit)",
+	FlagRateLimit:  "maximum requests per second to each endpoint (overrides the network profile; negative means unlimited)",
+	FlagRecord:     "record RPC calls to this cassette file for offline replay",
+	FlagReplay:     "answer RPC calls from this cassette file instead of the network",
+
+	FlagFeeStrategy:    "fee strategy: slow, normal, fast or custom",
+	FlagMaxFee:         "maxFeePerGas for the custom strategy, upper bound for the others (wei); gasPrice on legacy chains",
+	FlagMaxPriorityFee: "maxPriorityFeePerGas for the custom strategy, upper bound for the others (wei)",
+	FlagGasLimit:       "fixed gas limit (0 estimates automatically)",
+	FlagGasMultiplier:  "safety multiplier applied to gas estimates",
+	FlagGasCap:         "upper bound for the gas limit (0 means no limit)",
+	FlagNonceFile:      "file persisting in-flight nonces (empty disables persistence; disabled by default with -replay)",
+	FlagConfirmations:  "number of confirmations to wait for",
+	FlagNoWait:         "return right after sending without waiting for the receipt",
+	FlagTimeout:        "how long to wait for the receipt",
+	FlagSimulate:       "simulate with eth_call on the pending block before sending and abort on failure (defaults to the simulate config option, on by default)",
+
+	FlagTxs:          "list every transaction in the block (sender, recipient, value)",
+	FlagConcurrency:  "concurrent requests when fetching a block range",
+	FlagRecipient:    "recipient address",
+	FlagValue:        "amount to transfer (wei, default 0.0001 ETH)",
+	FlagAddress:      "Counter contract address or address book name",
+	FlagSave:         "save the deployed address under this name in the network's address book",
+	FlagBy:           "amount to increment by",
+	FlagCount:        "number of transactions to send concurrently",
+	FlagPending:      "read the pending state",
+	FlagFromBlock:    "first block",
+	FlagToBlock:      "last block (-1 means the latest block)",
+	FlagBump:         "fee increase over the original transaction in percent (at least 10)",
+	FlagDeployBlock:  "contract deployment block where the first run starts (0 finds it automatically)",
+	FlagChunk:        "blocks per query, reduced automatically when the node reports too many results",
+	FlagIndexDB:      "local event index file",
+	FlagIndexed:      "read from the local index instead of the node",
+	FlagFollowFrom:   "first block when the index is empty (0 follows new events only)",
+	FlagPoll:         "always poll instead of subscribing",
+	FlagPollInterval: "polling interval, also used to check for reorgs when subscribed",
+	FlagAuditFrom:    "first block to audit (0 starts at the deployment block)",
+	FlagAuditBlock:   "last block to audit (-1 means the latest block)",
+	FlagAuditStep:    "blocks per audited range",
+	FlagAuditIndexed: "sum events from the local index instead of the node, to validate the index",
+	FlagVerifyBlock:  "block to read the code at (-1 means the latest block)",
+	FlagCreate2:      "deploy deterministically through a CREATE2 factory, independent of the deployer nonce (saved as create2 by default)",
+	FlagSalt:         "CREATE2 salt: 32 bytes of hex, or any string (hashed with keccak256)",
+	FlagFactory:      "CREATE2 factory address (default: the deterministic deployment proxy)",
+	FlagManifest:     "deployment manifest recording every deploy and applied migration (empty disables recording)",
+	FlagMigrations:   "migration script (YAML)",
+
+	ErrUnknownCommand:   "unknown command %q",
+	ErrUnexpectedArgs:   "unexpected arguments: %v",
+	ErrNoRPC:            "no RPC endpoint configured for network %s",
+	ErrNoChainID:        "no chain ID configured for network %s",
+	ErrConnect:          "connection failed: %w",
+	ErrChainMismatch:    "chain ID mismatch: expected %s, node reports %s",
+	ErrInvalidFlag:      "invalid -%s: %v",
+	ErrInvalidBlock:     "invalid block %q: want a number, hash, latest, safe or finalized",
+	ErrInvalidRange:     "invalid block range %q",
+	ErrRangeTooLarge:    "block range %q is too large: at most %d blocks per query",
+	ErrFetchBlock:       "failed to fetch block: %w",
+	ErrFetchBlockNumber: "block %d: %w",
+	ErrRecoverSender:    "recover sender of %s: %w",
+	ErrInvalidRecipient: "invalid recipient address %q",
+	ErrInvalidValue:     "invalid value %q",
+	ErrInvalidIncrement: "invalid increment %q",
+	ErrTxUsage:          "usage: tx %s <hash> [flags]",
+	ErrTxMined:          "transaction %s is already mined",
+	ErrTxSender:         "transaction %s was sent by %s, not %s",
+	ErrGasCap:           "estimated gas %d exceeds cap %d",
+	ErrReverted:         "transaction %s reverted in block %d",
+	ErrPriceBumpTooLow:  "price bump must be at least 10%%",
+	ErrDeployBlock:      "cannot determine the deployment block (set it with -from): %w",
+	ErrAuditMismatch:    "%d of %d ranges do not match (blocks %d-%d)",
+	ErrRevertReason:     "execution reverted: %s",
+	ErrRevertPanic:      "execution reverted: panic %#x (%s)",
+	ErrRevertPanicCode:  "execution reverted: panic %#x",
+	ErrRevertCustom:     "execution reverted: %s",
+	ErrRevertUnknown:    "execution reverted with unrecognized data %s",
+	ErrRevertNoReason:   "execution reverted without a reason",
+	ErrSimulation:       "pre-flight simulation failed, transaction not sent: %s",
+	ErrNotCounter:       "%s is not a Counter contract",
+	ErrMigrateNoWait:    "migrations wait for every transaction, -no-wait is not supported",
+	ErrStaleManifest:    "%v (was the chain reset? remove this network's records from the manifest and run again)",
+	ErrRecordReplay:     "-record and -replay cannot be used together",
+	ErrNotRecorded:      "%v (the cassette does not contain this call; record it again with the same command and arguments)",
+	Warning:             "warning: %v",
+
+	ProgressFees:          "Fees (%s): %s",
+	ProgressGas:           "Gas: estimated %d, limit %d",
+	ProgressConfirmations: "Confirmations %d/%d",
+	ProgressReorged:       "Block containing %s was reorged, waiting for it to be included again",
+	ProgressDeployBlock:   "Contract deployed in block %d",
+	ProgressIndexChunk:    "Indexed blocks %d-%d: %d events",
+	ProgressAudit:         "Audited blocks %d-%d (of %d): %d events",
+	ProgressIndexShrink:   "Too many results, reducing chunk to %d blocks (%v)",
+	ProgressSubscribed:    "Subscribed to Increment events",
+	ProgressPolling:       "Node does not support subscriptions, polling every %s",
+	ProgressReconnect:     "Connection lost: %v, retrying in %s",
+	ProgressCreate2:       "CREATE2 address %s (factory %s, salt %s)",
+	ProgressMigration:     "Running migration %s (%s)",
+	ProgressFailover:      "Endpoint %s unavailable (%v), trying the next one",
+	ProgressRetry:         "RPC call failed (%v), retrying in %s (attempt %d)",
+
+	BlockNumber:      "Number: %d",
+	BlockHash:        "Hash: %s",
+	BlockParentHash:  "Parent hash: %s",
+	BlockTime:        "Timestamp: %d",
+	BlockMiner:       "Miner: %s",
+	BlockGas:         "Gas used: %d / %d",
+	BlockBaseFee:     "Base fee: %s wei",
+	BlockBlobGas:     "Blob gas used: %d (excess %d)",
+	BlockWithdrawals: "Withdrawals: %d",
+	BlockTxCount:     "Transactions: %d",
+	BlockTx:          "  %s  %s -> %s  %s wei",
+	BlockTxCreate:    "(contract creation)",
+
+	ReceiptStatus: "Status: %s (block %d, %d confirmations)",
+	ReceiptGas:    "Gas used: %d @ %s wei (fee %s wei)",
+
+	TransferSent:            "Transaction sent 🎉\nTx Hash: %s",
+	CounterDeployed:         "Counter deployed at: %s",
+	CounterDeployTx:         "Deploy transaction sent: %s",
+	CounterTxSent:           "%s transaction sent: %s",
+	CounterValue:            "Current counter value: %s",
+	CounterIncrement:        "block %d tx %s: Increment(by=%s)",
+	CounterIncrementRemoved: "block %d tx %s: Increment(by=%s) removed by reorg",
+	CounterCreate2Existing:  "Counter already deployed at %s, skipping",
+	ReplacementSent:         "Replacement transaction sent: %s (nonce %d, %s)",
+	ReplacementMined:        "Mined: %s",
+	IndexResult:             "Indexed blocks %d-%d, %d new Increment events",
+	IndexUpToDate:           "Index is up to date (block %d)",
+	AuditRangeOK:            "blocks %d-%d: x %s -> %s, %d events, ok",
+	AuditRangeMismatch:      "blocks %d-%d: x changed by %s but %d events sum to %s, MISMATCH",
+	VerifyFullMatch:         "Code at %s fully matches Counter (compiler %s)",
+	VerifyPartialMatch:      "Code at %s matches Counter but metadata differs (embedded compiler %s, deployed %s)",
+	VerifyMismatch:          "Code at %s (%d bytes, compiler %s) does not match Counter",
+	VerifyNoCode:            "No contract code at %s",
+	VerifyImmutable:         "  immutable @%s = %s",
+	MigrationApplied:        "Migration %s (%s) applied, contract %s",
+	MigrationTx:             "  transaction %s, block %d",
+	MigrationSkipped:        "Migration %s (%s) already applied, skipping",
+}
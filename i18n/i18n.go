@@ -0,0 +1,93 @@
+// Package i18n 命令行提示、错误和结果的中英文消息目录。
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Lang 消息语言
+type Lang string
+
+const (
+	ZH Lang = "zh"
+	EN Lang = "en"
+)
+
+// Langs 支持的全部语言
+var Langs = []Lang{ZH, EN}
+
+// Default 未指定或无法识别环境变量时使用的语言
+const Default = ZH
+
+// catalogs 各语言的消息，每种语言都必须包含 keys.go 中的全部 Key
+var catalogs = map[Lang]map[Key]string{
+	ZH: zh,
+	EN: en,
+}
+
+// Parse 解析语言名，接受 zh、en 以及 zh_CN.UTF-8、en-US 这类 locale 写法
+func Parse(s string) (Lang, error) {
+	name, _, _ := strings.Cut(strings.ToLower(s), ".")
+	name, _, _ = strings.Cut(name, "@")
+	name, _, _ = strings.Cut(name, "_")
+	name, _, _ = strings.Cut(name, "-")
+	if l := Lang(name); slices.Contains(Langs, l) {
+		return l, nil
+	}
+	return "", fmt.Errorf("unsupported language %q (available: %v)", s, Langs)
+}
+
+// FromEnv 按 LC_ALL、LC_MESSAGES、LANG 的优先级选择语言，
+// 都未设置或无法识别（例如 C、POSIX）时返回 Default
+func FromEnv() Lang {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			if l, err := Parse(v); err == nil {
+				return l
+			}
+			return Default
+		}
+	}
+	return Default
+}
+
+// Printer 按语言格式化消息
+type Printer struct {
+	lang Lang
+}
+
+// NewPrinter 创建指定语言的 Printer，不支持的语言使用 Default
+func NewPrinter(lang Lang) *Printer {
+	if _, ok := catalogs[lang]; !ok {
+		lang = Default
+	}
+	return &Printer{lang: lang}
+}
+
+// Lang 当前语言
+func (p *Printer) Lang() Lang {
+	return p.lang
+}
+
+// Sprintf 按 key 对应的格式串格式化，格式与 fmt 相同
+func (p *Printer) Sprintf(key Key, args ...any) string {
+	return fmt.Sprintf(p.format(key), args...)
+}
+
+// Errorf 与 Sprintf 相同但返回 error，格式串中的 %w 会包装原错误
+func (p *Printer) Errorf(key Key, args ...any) error {
+	return fmt.Errorf(p.format(key), args...)
+}
+
+func (p *Printer) format(key Key) string {
+	if s, ok := catalogs[p.lang][key]; ok {
+		return s
+	}
+	if s, ok := catalogs[Default][key]; ok {
+		return s
+	}
+	return string(key)
+}
@@ -0,0 +1,140 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// declaredKeys 从 keys.go 中收集所有 Key 常量，新增常量时无需同步修改测试
+func declaredKeys(t *testing.T) []Key {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "keys.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []Key
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if ident, ok := vs.Type.(*ast.Ident); !ok || ident.Name != "Key" {
+				continue
+			}
+			for _, v := range vs.Values {
+				s, err := strconv.Unquote(v.(*ast.BasicLit).Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys = append(keys, Key(s))
+			}
+		}
+	}
+	if len(keys) == 0 {
+		t.Fatal("no keys found in keys.go")
+	}
+	return keys
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	keys := declaredKeys(t)
+	for _, lang := range Langs {
+		catalog, ok := catalogs[lang]
+		if !ok {
+			t.Errorf("no catalog for %s", lang)
+			continue
+		}
+		for _, key := range keys {
+			if catalog[key] == "" {
+				t.Errorf("%s: missing message for %s", lang, key)
+			}
+		}
+		for key := range catalog {
+			if !slices.Contains(keys, key) {
+				t.Errorf("%s: message for undeclared key %s", lang, key)
+			}
+		}
+	}
+}
+
+var verbRE = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z%]`)
+
+// 各语言的格式串必须使用相同顺序的动词，否则参数会错位
+func TestCatalogsVerbs(t *testing.T) {
+	for _, key := range declaredKeys(t) {
+		want := verbRE.FindAllString(catalogs[Default][key], -1)
+		for _, lang := range Langs {
+			if got := verbRE.FindAllString(catalogs[lang][key], -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %s uses verbs %v, %s uses %v", key, lang, got, Default, want)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Lang
+	}{
+		{"zh", ZH},
+		{"en", EN},
+		{"EN", EN},
+		{"zh_CN.UTF-8", ZH},
+		{"zh_TW", ZH},
+		{"en-US", EN},
+		{"en_GB.UTF-8@euro", EN},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "C", "POSIX", "fr_FR.UTF-8"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		lcAll, lcMessages, lang string
+		want                    Lang
+	}{
+		{"", "", "", Default},
+		{"", "", "en_US.UTF-8", EN},
+		{"", "en_US.UTF-8", "zh_CN.UTF-8", EN},
+		{"zh_CN.UTF-8", "en_US.UTF-8", "en_US.UTF-8", ZH},
+		{"C", "", "en_US.UTF-8", Default},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_MESSAGES", tt.lcMessages)
+		t.Setenv("LANG", tt.lang)
+		if got := FromEnv(); got != tt.want {
+			t.Errorf("FromEnv() with LC_ALL=%q LC_MESSAGES=%q LANG=%q = %q, want %q",
+				tt.lcAll, tt.lcMessages, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	p := NewPrinter(EN)
+	if got := p.Sprintf(CounterValue, "42"); got != "Current counter value: 42" {
+		t.Errorf("Sprintf = %q", got)
+	}
+	if got := NewPrinter("fr").Lang(); got != Default {
+		t.Errorf("NewPrinter(fr).Lang() = %q, want %q", got, Default)
+	}
+	if got := p.Sprintf("no.such.key"); got != "no.such.key" {
+		t.Errorf("unknown key = %q", got)
+	}
+}
@@ -0,0 +1,186 @@
+package i18n
+
+// Key 消息的标识，对应各语言目录中的格式串
+type Key string
+
+// 命令与用法
+const (
+	UsageHeader   Key = "usage.header"
+	UsageCommands Key = "usage.commands"
+
+	CmdBlock          Key = "cmd.block"
+	CmdTransfer       Key = "cmd.transfer"
+	CmdCounter        Key = "cmd.counter"
+	CmdTx             Key = "cmd.tx"
+	CmdCounterDeploy  Key = "cmd.counter.deploy"
+	CmdCounterInc     Key = "cmd.counter.inc"
+	CmdCounterIncBy   Key = "cmd.counter.inc_by"
+	CmdCounterGet     Key = "cmd.counter.get"
+	CmdCounterEvents  Key = "cmd.counter.events"
+	CmdCounterIndex   Key = "cmd.counter.index"
+	CmdCounterFollow  Key = "cmd.counter.follow"
+	CmdCounterAudit   Key = "cmd.counter.audit"
+	CmdCounterVerify  Key = "cmd.counter.verify"
+	CmdCounterMigrate Key = "cmd.counter.migrate"
+	CmdTxSpeedup      Key = "cmd.tx.speedup"
+	CmdTxCancel       Key = "cmd.tx.cancel"
+)
+
+// 参数说明
+const (
+	FlagConfig     Key = "flag.config"
+	FlagNetwork    Key = "flag.network"
+	FlagRPC        Key = "flag.rpc"
+	FlagChainID    Key = "flag.chain_id"
+	FlagKey        Key = "flag.key"
+	FlagOutput     Key = "flag.output"
+	FlagLang       Key = "flag.lang"
+	FlagRPCTimeout Key = "flag.rpc_timeout"
+	FlagRateLimit  Key = "flag.rate_limit"
+	FlagRecord     Key = "flag.record"
+	FlagReplay     Key = "flag.replay"
+
+	FlagFeeStrategy    Key = "flag.fee_strategy"
+	FlagMaxFee         Key = "flag.max_fee"
+	FlagMaxPriorityFee Key = "flag.max_priority_fee"
+	FlagGasLimit       Key = "flag.gas_limit"
+	FlagGasMultiplier  Key = "flag.gas_multiplier"
+	FlagGasCap         Key = "flag.gas_cap"
+	FlagNonceFile      Key = "flag.nonce_file"
+	FlagConfirmations  Key = "flag.confirmations"
+	FlagNoWait         Key = "flag.no_wait"
+	FlagTimeout        Key = "flag.timeout"
+	FlagSimulate       Key = "flag.simulate"
+
+	FlagTxs          Key = "flag.txs"
+	FlagConcurrency  Key = "flag.concurrency"
+	FlagRecipient    Key = "flag.recipient"
+	FlagValue        Key = "flag.value"
+	FlagAddress      Key = "flag.address"
+	FlagSave         Key = "flag.save"
+	FlagBy           Key = "flag.by"
+	FlagCount        Key = "flag.count"
+	FlagPending      Key = "flag.pending"
+	FlagFromBlock    Key = "flag.from_block"
+	FlagToBlock      Key = "flag.to_block"
+	FlagBump         Key = "flag.bump"
+	FlagDeployBlock  Key = "flag.deploy_block"
+	FlagChunk        Key = "flag.chunk"
+	FlagIndexDB      Key = "flag.index_db"
+	FlagIndexed      Key = "flag.indexed"
+	FlagFollowFrom   Key = "flag.follow_from"
+	FlagPoll         Key = "flag.poll"
+	FlagPollInterval Key = "flag.poll_interval"
+	FlagAuditFrom    Key = "flag.audit_from"
+	FlagAuditBlock   Key = "flag.audit_block"
+	FlagAuditStep    Key = "flag.audit_step"
+	FlagAuditIndexed Key = "flag.audit_indexed"
+	FlagVerifyBlock  Key = "flag.verify_block"
+	FlagCreate2      Key = "flag.create2"
+	FlagSalt         Key = "flag.salt"
+	FlagFactory      Key = "flag.factory"
+	FlagManifest     Key = "flag.manifest"
+	FlagMigrations   Key = "flag.migrations"
+)
+
+// 错误
+const (
+	ErrUnknownCommand   Key = "err.unknown_command"
+	ErrUnexpectedArgs   Key = "err.unexpected_args"
+	ErrNoRPC            Key = "err.no_rpc"
+	ErrNoChainID        Key = "err.no_chain_id"
+	ErrConnect          Key = "err.connect"
+	ErrChainMismatch    Key = "err.chain_mismatch"
+	ErrInvalidFlag      Key = "err.invalid_flag"
+	ErrInvalidBlock     Key = "err.invalid_block"
+	ErrInvalidRange     Key = "err.invalid_range"
+	ErrRangeTooLarge    Key = "err.range_too_large"
+	ErrFetchBlock       Key = "err.fetch_block"
+	ErrFetchBlockNumber Key = "err.fetch_block_number"
+	ErrRecoverSender    Key = "err.recover_sender"
+	ErrInvalidRecipient Key = "err.invalid_recipient"
+	ErrInvalidValue     Key = "err.invalid_value"
+	ErrInvalidIncrement Key = "err.invalid_increment"
+	ErrTxUsage          Key = "err.tx_usage"
+	ErrTxMined          Key = "err.tx_mined"
+	ErrTxSender         Key = "err.tx_sender"
+	ErrGasCap           Key = "err.gas_cap"
+	ErrReverted         Key = "err.reverted"
+	ErrPriceBumpTooLow  Key = "err.price_bump_too_low"
+	ErrDeployBlock      Key = "err.deploy_block"
+	ErrAuditMismatch    Key = "err.audit_mismatch"
+	ErrRevertReason     Key = "err.revert_reason"
+	ErrRevertPanic      Key = "err.revert_panic"
+	ErrRevertPanicCode  Key = "err.revert_panic_code"
+	ErrRevertCustom     Key = "err.revert_custom"
+	ErrRevertUnknown    Key = "err.revert_unknown"
+	ErrRevertNoReason   Key = "err.revert_no_reason"
+	ErrSimulation       Key = "err.simulation"
+	ErrNotCounter       Key = "err.not_counter"
+	ErrMigrateNoWait    Key = "err.migrate_no_wait"
+	ErrStaleManifest    Key = "err.stale_manifest"
+	ErrRecordReplay     Key = "err.record_replay"
+	ErrNotRecorded      Key = "err.not_recorded"
+	Warning             Key = "warning"
+)
+
+// 进度提示
+const (
+	ProgressFees          Key = "progress.fees"
+	ProgressGas           Key = "progress.gas"
+	ProgressConfirmations Key = "progress.confirmations"
+	ProgressReorged       Key = "progress.reorged"
+	ProgressDeployBlock   Key = "progress.deploy_block"
+	ProgressIndexChunk    Key = "progress.index_chunk"
+	ProgressIndexShrink   Key = "progress.index_shrink"
+	ProgressAudit         Key = "progress.audit"
+	ProgressSubscribed    Key = "progress.subscribed"
+	ProgressPolling       Key = "progress.polling"
+	ProgressReconnect     Key = "progress.reconnect"
+	ProgressCreate2       Key = "progress.create2"
+	ProgressMigration     Key = "progress.migration"
+	ProgressFailover      Key = "progress.failover"
+	ProgressRetry         Key = "progress.retry"
+)
+
+// 结果
+const (
+	BlockNumber      Key = "block.number"
+	BlockHash        Key = "block.hash"
+	BlockParentHash  Key = "block.parent_hash"
+	BlockTime        Key = "block.time"
+	BlockMiner       Key = "block.miner"
+	BlockGas         Key = "block.gas"
+	BlockBaseFee     Key = "block.base_fee"
+	BlockBlobGas     Key = "block.blob_gas"
+	BlockWithdrawals Key = "block.withdrawals"
+	BlockTxCount     Key = "block.tx_count"
+	BlockTx          Key = "block.tx"
+	BlockTxCreate    Key = "block.tx_create"
+
+	ReceiptStatus Key = "receipt.status"
+	ReceiptGas    Key = "receipt.gas"
+
+	TransferSent            Key = "transfer.sent"
+	CounterDeployed         Key = "counter.deployed"
+	CounterDeployTx         Key = "counter.deploy_tx"
+	CounterTxSent           Key = "counter.tx_sent"
+	CounterValue            Key = "counter.value"
+	CounterIncrement        Key = "counter.increment"
+	CounterIncrementRemoved Key = "counter.increment_removed"
+	CounterCreate2Existing  Key = "counter.create2_existing"
+	ReplacementSent         Key = "replacement.sent"
+	ReplacementMined        Key = "replacement.mined"
+	IndexResult             Key = "index.result"
+	IndexUpToDate           Key = "index.up_to_date"
+	AuditRangeOK            Key = "audit.range_ok"
+	AuditRangeMismatch      Key = "audit.range_mismatch"
+	VerifyFullMatch         Key = "verify.full_match"
+	VerifyPartialMatch      Key = "verify.partial_match"
+	VerifyMismatch          Key = "verify.mismatch"
+	VerifyNoCode            Key = "verify.no_code"
+	VerifyImmutable         Key = "verify.immutable"
+	MigrationApplied        Key = "migration.applied"
+	MigrationTx             Key = "migration.tx"
+	MigrationSkipped        Key = "migration.skipped"
+)
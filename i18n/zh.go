@@ -0,0 +1,170 @@
+package i18n
+
+var zh = map[Key]string{
+	UsageHeader:   "用法: %s <command> [flags]",
+	UsageCommands: "可用命令:",
+
+	CmdBlock:          "查询区块信息",
+	CmdTransfer:       "发送 ETH 转账",
+	CmdCounter:        "Counter 合约操作（deploy/inc/inc-by/get/events/index/follow/audit/verify/migrate）",
+	CmdTx:             "处理卡住的交易（speedup/cancel）",
+	CmdCounterDeploy:  "部署新的 Counter 合约",
+	CmdCounterInc:     "调用 inc()",
+	CmdCounterIncBy:   "调用 incBy(by)",
+	CmdCounterGet:     "读取当前计数 x",
+	CmdCounterEvents:  "查询 Increment 事件",
+	CmdCounterIndex:   "把 Increment 事件回填到本地索引",
+	CmdCounterFollow:  "持续跟随 Increment 事件并写入本地索引",
+	CmdCounterAudit:   "按区块区间核对 x 与 Increment 事件之和",
+	CmdCounterVerify:  "核对地址上的代码是否为 Counter 合约",
+	CmdCounterMigrate: "执行部署迁移并把结果记入部署清单",
+	CmdTxSpeedup:      "以更高手续费重发同 nonce 的交易",
+	CmdTxCancel:       "用同 nonce 的 0 值自转账取消交易",
+
+	FlagConfig:     "配置文件路径（默认 $SEPOLIA_BLOCK_CONFIG 或 ./%s）",
+	FlagNetwork:    "网络配置名，例如 sepolia、anvil、mainnet-fork",
+	FlagRPC:        "RPC 节点地址（覆盖网络配置），逗号分隔多个节点时自动切换",
+	FlagChainID:    "链 ID（覆盖网络配置）",
+	FlagKey:        "私钥来源：env:<变量名>、hex:<私钥>、keystore:<文件>、mnemonic:<变量名>[#路径]、clef:<URL>[#地址]、remote:<URL>[#地址]",
+	FlagOutput:     "输出格式：text、json、ndjson 或 csv",
+	FlagLang:       "消息语言：zh 或 en（默认按 LANG 环境变量）",
+	FlagRPCTimeout: "单次 RPC 调用（含重试）的超时，0 表示不限",
+	FlagRateLimit:  "每个节点每秒请求数上限（覆盖网络配置），负数表示不限",
+	FlagRecord:     "把 RPC 调用录制到 cassette 文件，供离线回放",
+	FlagReplay:     "从 cassette 文件回放 RPC 应答，不连接网络",
+
+	FlagFeeStrategy:    "手续费策略：slow、normal、fast 或 custom",
+	FlagMaxFee:         "custom 策略的 maxFeePerGas，其他策略下为上限（wei）；legacy 链上对应 gasPrice",
+	FlagMaxPriorityFee: "custom 策略的 maxPriorityFeePerGas，其他策略下为上限（wei）",
+	FlagGasLimit:       "固定 gas limit（0 表示自动估算）",
+	FlagGasMultiplier:  "估算 gas 的安全系数",
+	FlagGasCap:         "gas limit 上限（0 表示不限制）",
+	FlagNonceFile:      "在途 nonce 的持久化文件（空表示不持久化；-replay 时默认不持久化）",
+	FlagConfirmations:  "等待的确认数",
+	FlagNoWait:         "发送后立即返回，不等待回执",
+	FlagTimeout:        "等待回执的超时时间",
+	FlagSimulate:       "发送前先在 pending 区块上用 eth_call 模拟，失败时不发送（默认按配置项 simulate，内置为开启）",
+
+	FlagTxs:          "输出区块内每笔交易（发送方、接收方、金额）",
+	FlagConcurrency:  "查询区块范围时的并发数",
+	FlagRecipient:    "收款地址",
+	FlagValue:        "转账金额（wei，默认 0.0001 ETH）",
+	FlagAddress:      "Counter 合约地址或地址簿中的名字",
+	FlagSave:         "部署后以该名字写入当前网络的地址簿",
+	FlagBy:           "增加的数值",
+	FlagCount:        "并发发送的交易笔数",
+	FlagPending:      "读取 pending 状态",
+	FlagFromBlock:    "起始区块",
+	FlagToBlock:      "结束区块（-1 表示最新区块）",
+	FlagBump:         "相对原交易的加价百分比（至少 10）",
+	FlagDeployBlock:  "合约部署区块，首次索引从这里开始（0 表示自动查找）",
+	FlagChunk:        "每次查询的区块数，节点报告结果过多时自动缩小",
+	FlagIndexDB:      "本地事件索引文件",
+	FlagIndexed:      "从本地索引读取，不查询节点",
+	FlagFollowFrom:   "索引为空时的起始区块（0 表示只跟随新事件）",
+	FlagPoll:         "强制轮询，不使用订阅",
+	FlagPollInterval: "轮询间隔，订阅时也按此间隔检查重组",
+	FlagAuditFrom:    "核对的起始区块（0 表示从部署区块开始）",
+	FlagAuditBlock:   "核对到的区块（-1 表示最新区块）",
+	FlagAuditStep:    "每个核对区间的区块数",
+	FlagAuditIndexed: "用本地索引中的事件核对，用于检验索引",
+	FlagVerifyBlock:  "读取代码的区块（-1 表示最新区块）",
+	FlagCreate2:      "通过 CREATE2 工厂确定性部署，地址与部署账户的 nonce 无关（默认写入地址簿的 create2）",
+	FlagSalt:         "CREATE2 的 salt：32 字节十六进制，或任意字符串（取 keccak256）",
+	FlagFactory:      "CREATE2 工厂地址（默认确定性部署代理）",
+	FlagManifest:     "部署清单，记录每次部署和已执行的迁移（为空时不记录）",
+	FlagMigrations:   "迁移脚本（YAML）",
+
+	ErrUnknownCommand:   "未知命令 %q",
+	ErrUnexpectedArgs:   "多余的参数：%v",
+	ErrNoRPC:            "网络 %s 未配置 RPC 节点",
+	ErrNoChainID:        "网络 %s 未配置链 ID",
+	ErrConnect:          "连接失败：%w",
+	ErrChainMismatch:    "链 ID 不一致：期望 %s，节点为 %s",
+	ErrInvalidFlag:      "无效的 -%s：%v",
+	ErrInvalidBlock:     "无效的区块 %q：应为区块号、区块哈希、latest、safe 或 finalized",
+	ErrInvalidRange:     "无效的区块范围 %q",
+	ErrRangeTooLarge:    "区块范围 %q 过大：一次最多查询 %d 个区块",
+	ErrFetchBlock:       "区块获取失败：%w",
+	ErrFetchBlockNumber: "区块 %d：%w",
+	ErrRecoverSender:    "无法恢复交易 %s 的发送方：%w",
+	ErrInvalidRecipient: "无效的收款地址 %q",
+	ErrInvalidValue:     "无效的金额 %q",
+	ErrInvalidIncrement: "无效的增量 %q",
+	ErrTxUsage:          "用法: tx %s <hash> [flags]",
+	ErrTxMined:          "交易 %s 已上链",
+	ErrTxSender:         "交易 %s 由 %s 发送，而不是 %s",
+	ErrGasCap:           "估算 gas %d 超过上限 %d",
+	ErrReverted:         "交易 %s 在区块 %d 执行失败",
+	ErrPriceBumpTooLow:  "加价至少为 10%%",
+	ErrDeployBlock:      "无法确定部署区块（请用 -from 指定）：%w",
+	ErrAuditMismatch:    "%d/%d 个区间不一致（区块 %d-%d）",
+	ErrRevertReason:     "执行回滚：%s",
+	ErrRevertPanic:      "执行回滚：panic %#x（%s）",
+	ErrRevertPanicCode:  "执行回滚：panic %#x",
+	ErrRevertCustom:     "执行回滚：%s",
+	ErrRevertUnknown:    "执行回滚，无法识别的回滚数据 %s",
+	ErrRevertNoReason:   "执行回滚，未给出原因",
+	ErrSimulation:       "发送前模拟失败，交易未发送：%s",
+	ErrNotCounter:       "%s 上不是 Counter 合约",
+	ErrMigrateNoWait:    "迁移需要等待每笔交易确认，不支持 -no-wait",
+	ErrStaleManifest:    "%v（测试链是否被重置？删除清单中该网络的记录后重新运行）",
+	ErrRecordReplay:     "-record 和 -replay 不能同时使用",
+	ErrNotRecorded:      "%v（cassette 中没有这次调用，请用相同的命令和参数重新录制）",
+	Warning:             "警告：%v",
+
+	ProgressFees:          "手续费（%s）：%s",
+	ProgressGas:           "Gas：估算 %d，上限 %d",
+	ProgressConfirmations: "确认数 %d/%d",
+	ProgressReorged:       "交易 %s 所在区块被重组，重新等待打包",
+	ProgressDeployBlock:   "合约部署于区块 %d",
+	ProgressIndexChunk:    "已索引区块 %d-%d：%d 个事件",
+	ProgressAudit:         "已核对区块 %d-%d（共到 %d）：%d 个事件",
+	ProgressIndexShrink:   "查询结果过多，分块缩小为 %d 个区块（%v）",
+	ProgressSubscribed:    "已订阅 Increment 事件",
+	ProgressPolling:       "节点不支持订阅，每 %s 轮询一次",
+	ProgressReconnect:     "连接中断：%v，%s 后重试",
+	ProgressCreate2:       "CREATE2 预测地址 %s（工厂 %s，salt %s）",
+	ProgressMigration:     "执行迁移 %s（%s）",
+	ProgressFailover:      "节点 %s 不可用（%v），改用下一个节点",
+	ProgressRetry:         "RPC 调用失败（%v），%s 后重试（第 %d 次）",
+
+	BlockNumber:      "区块号: %d",
+	BlockHash:        "区块哈希: %s",
+	BlockParentHash:  "父区块哈希: %s",
+	BlockTime:        "时间戳: %d",
+	BlockMiner:       "出块地址: %s",
+	BlockGas:         "Gas 使用: %d / %d",
+	BlockBaseFee:     "基础费用: %s wei",
+	BlockBlobGas:     "Blob gas 使用: %d（excess %d）",
+	BlockWithdrawals: "提款数量: %d",
+	BlockTxCount:     "交易数量: %d",
+	BlockTx:          "  %s  %s -> %s  %s wei",
+	BlockTxCreate:    "(合约创建)",
+
+	ReceiptStatus: "状态: %s（区块 %d，%d 个确认）",
+	ReceiptGas:    "Gas 使用: %d @ %s wei（手续费 %s wei）",
+
+	TransferSent:            "交易已发送 🎉\nTx Hash: %s",
+	CounterDeployed:         "Counter 合约地址: %s",
+	CounterDeployTx:         "部署交易已发送: %s",
+	CounterTxSent:           "%s 交易已发送: %s",
+	CounterValue:            "当前计数: %s",
+	CounterIncrement:        "区块 %d 交易 %s: Increment(by=%s)",
+	CounterIncrementRemoved: "区块 %d 交易 %s: Increment(by=%s) 因重组撤销",
+	CounterCreate2Existing:  "Counter 已部署在 %s，跳过部署",
+	ReplacementSent:         "替换交易已发送: %s（nonce %d，%s）",
+	ReplacementMined:        "已上链: %s",
+	IndexResult:             "已索引区块 %d-%d，新增 %d 个 Increment 事件",
+	IndexUpToDate:           "索引已是最新（区块 %d）",
+	AuditRangeOK:            "区块 %d-%d：x %s → %s，%d 个事件，一致",
+	AuditRangeMismatch:      "区块 %d-%d：x 变化 %s，%d 个事件合计 %s，不一致",
+	VerifyFullMatch:         "%s 上的代码与 Counter 完全一致（编译器 %s）",
+	VerifyPartialMatch:      "%s 上的代码与 Counter 一致，元数据不同（内置编译器 %s，链上 %s）",
+	VerifyMismatch:          "%s 上的代码（%d 字节，编译器 %s）与 Counter 不一致",
+	VerifyNoCode:            "%s 上没有合约代码",
+	VerifyImmutable:         "  immutable @%s = %s",
+	MigrationApplied:        "迁移 %s（%s）完成，合约 %s",
+	MigrationTx:             "  交易 %s，区块 %d",
+	MigrationSkipped:        "迁移 %s（%s）此前已执行，跳过",
+}
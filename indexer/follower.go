@@ -0,0 +1,164 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"sepolia-block/counter"
+)
+
+// IncrementWatcher 查询并订阅 Increment 事件，*counter.Counter 满足
+type IncrementWatcher interface {
+	IncrementFilterer
+	WatchIncrement(opts *bind.WatchOpts, sink chan<- *counter.CounterIncrement) (event.Subscription, error)
+}
+
+// Follower 持续跟随链上的 Increment 事件并写入事件库。
+//
+// 节点支持订阅（websocket、IPC）时先订阅 WatchIncrement，再把事件库补齐到当前区块，
+// 订阅建立前后的区块都由分块查询覆盖，不会漏掉事件；订阅收到的事件即时写入，
+// log.Removed 的事件从库中删除。HTTP 节点无法订阅时退回按 PollInterval 轮询。
+// 两种方式都会定期检查检查点，发现重组后回退并重新索引。
+// 订阅或查询出错时按指数退避重新连接，重连后从上次索引到的区块继续。
+type Follower struct {
+	*Indexer
+	watcher IncrementWatcher
+
+	PollInterval time.Duration // 轮询间隔，订阅模式下用于推进索引位置和检查重组
+	Poll         bool          // 强制轮询，不尝试订阅
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+
+	// OnConnect 可选，每次（重新）连接后回调，subscribed 表示是否使用订阅
+	OnConnect func(subscribed bool)
+	// OnError 可选，连接中断、即将在 retry 后重试时回调
+	OnError func(err error, retry time.Duration)
+}
+
+// NewFollower 用 ix 的事件库和分块设置创建 Follower
+func NewFollower(ix *Indexer, watcher IncrementWatcher) *Follower {
+	return &Follower{
+		Indexer:      ix,
+		watcher:      watcher,
+		PollInterval: 12 * time.Second,
+		MinBackoff:   time.Second,
+		MaxBackoff:   time.Minute,
+	}
+}
+
+// Run 跟随事件直到 ctx 结束。事件库为空时从 start 开始索引。
+func (f *Follower) Run(ctx context.Context, start uint64) error {
+	backoff := f.MinBackoff
+	for {
+		connected, err := f.session(ctx, start)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if connected {
+			backoff = f.MinBackoff
+		}
+		if f.OnError != nil {
+			f.OnError(err, backoff)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, f.MaxBackoff)
+	}
+}
+
+// session 建立一次连接并处理事件，返回时连接已失效；connected 表示曾经成功补齐过索引
+func (f *Follower) session(ctx context.Context, start uint64) (connected bool, err error) {
+	var (
+		sink   chan *counter.CounterIncrement
+		subErr <-chan error
+	)
+	if !f.Poll {
+		sink = make(chan *counter.CounterIncrement, 64)
+		sub, err := f.watcher.WatchIncrement(&bind.WatchOpts{Context: ctx}, sink)
+		switch {
+		case errors.Is(err, rpc.ErrNotificationsUnsupported):
+			sink = nil // HTTP 节点，只能轮询
+		case err != nil:
+			return false, err
+		default:
+			defer sub.Unsubscribe()
+			subErr = sub.Err()
+		}
+	}
+	if f.OnConnect != nil {
+		f.OnConnect(subErr != nil)
+	}
+	if err := f.sync(ctx, start); err != nil {
+		return false, err
+	}
+
+	ticker := time.NewTicker(f.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err := <-subErr:
+			if err == nil {
+				err = errors.New("subscription closed")
+			}
+			return true, err
+		case ev := <-sink:
+			if err := f.apply(ev); err != nil {
+				return true, err
+			}
+		case <-ticker.C:
+			if err := f.sync(ctx, start); err != nil {
+				return true, err
+			}
+		}
+	}
+}
+
+// sync 处理重组后把索引补齐到当前区块
+func (f *Follower) sync(ctx context.Context, start uint64) error {
+	if _, err := f.Reconcile(ctx); err != nil {
+		return err
+	}
+	head, err := f.head(ctx)
+	if err != nil {
+		return err
+	}
+	_, _, err = f.Backfill(ctx, start, head)
+	return err
+}
+
+// apply 写入订阅收到的事件，被重组撤销的事件从库中删除
+func (f *Follower) apply(log *counter.CounterIncrement) error {
+	ev := newEvent(log)
+	var (
+		changed bool
+		err     error
+	)
+	if log.Raw.Removed {
+		changed, err = f.store.Remove(f.chainID, f.address, ev)
+	} else {
+		changed, err = f.store.Put(f.chainID, f.address, ev)
+	}
+	if err == nil && changed {
+		f.notify([]Event{ev}, log.Raw.Removed)
+	}
+	return err
+}
+
+// head 返回当前区块号
+func (f *Follower) head(ctx context.Context) (uint64, error) {
+	header, err := f.headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
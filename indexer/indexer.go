@@ -0,0 +1,251 @@
+// Package indexer 把 Counter 合约的 Increment 事件分块回填到本地事件库，
+// 中断后从上次索引到的区块继续。
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"sepolia-block/counter"
+)
+
+// DefaultChunkSize 每次 eth_getLogs 查询的默认区块数
+const DefaultChunkSize = 2000
+
+// growAfter 分块缩小后，连续成功多少次再尝试翻倍
+const growAfter = 5
+
+// IncrementFilterer 查询 Increment 事件，*counter.Counter 和 *counter.CounterFilterer 都满足
+type IncrementFilterer interface {
+	FilterIncrement(opts *bind.FilterOpts) (*counter.CounterIncrementIterator, error)
+}
+
+// HeaderBackend 记录检查点和检测重组所需的节点接口
+type HeaderBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// CodeBackend 查找部署区块所需的节点接口
+type CodeBackend interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// Chunk 一次分块查询的结果
+type Chunk struct {
+	From, To uint64
+	Events   int
+}
+
+// Indexer 回填单个合约的 Increment 事件
+type Indexer struct {
+	filterer IncrementFilterer
+	headers  HeaderBackend
+	store    *Store
+	chainID  *big.Int
+	address  common.Address
+
+	// ChunkSize 每次查询的区块数上限。节点报告结果过多时减半重试，
+	// 之后每连续成功几次翻倍一次，直到恢复为 ChunkSize。
+	ChunkSize uint64
+	// OnChunk 可选，每个分块写入事件库后回调
+	OnChunk func(Chunk)
+	// OnShrink 可选，分块因结果过多而缩小时回调
+	OnShrink func(size uint64, err error)
+	// OnEvent 可选，事件写入事件库或因重组被删除（removed 为 true）时回调
+	OnEvent func(ev Event, removed bool)
+}
+
+// New 创建 address 处合约的 Indexer
+func New(filterer IncrementFilterer, headers HeaderBackend, store *Store, chainID *big.Int, address common.Address) *Indexer {
+	return &Indexer{
+		filterer:  filterer,
+		headers:   headers,
+		store:     store,
+		chainID:   chainID,
+		address:   address,
+		ChunkSize: DefaultChunkSize,
+	}
+}
+
+// Backfill 索引区块 [start, end] 内的事件。已有索引时从上次索引到的区块之后继续，
+// start 只在首次索引时生效（通常是合约的部署区块）。返回本次实际索引的起始区块和新增事件数。
+func (ix *Indexer) Backfill(ctx context.Context, start, end uint64) (from uint64, added int, err error) {
+	from = start
+	if first, ok, err := ix.store.First(ix.chainID, ix.address); err != nil {
+		return 0, 0, err
+	} else if ok {
+		from = first // 重组后索引被清空，从原来的起始区块重新索引
+	}
+	if last, ok, err := ix.store.Last(ix.chainID, ix.address); err != nil {
+		return 0, 0, err
+	} else if ok {
+		from = last + 1
+	}
+	limit := max(ix.ChunkSize, 1)
+	size, streak := limit, 0
+	for next := from; next <= end; {
+		to := min(next+size-1, end)
+		// 先取区间末尾的区块哈希再查询事件：之后发生的重组一定会让检查点对不上，
+		// 下次 Reconcile 时回退重新索引
+		header, err := ix.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(to))
+		if err != nil {
+			return from, added, err
+		}
+		events, err := ix.filter(ctx, next, to)
+		if IsTooManyResults(err) && to > next {
+			size, streak = max((to-next+1)/2, 1), 0
+			if ix.OnShrink != nil {
+				ix.OnShrink(size, err)
+			}
+			continue
+		}
+		if err != nil {
+			return from, added, fmt.Errorf("filter blocks %d-%d: %w", next, to, err)
+		}
+		newEvents, removed, err := ix.store.Append(ix.chainID, ix.address, next, to, header.Hash(), events)
+		if err != nil {
+			return from, added, err
+		}
+		ix.notify(removed, true)
+		ix.notify(newEvents, false)
+		added += len(newEvents)
+		if ix.OnChunk != nil {
+			ix.OnChunk(Chunk{From: next, To: to, Events: len(events)})
+		}
+		next = to + 1
+		if streak++; streak >= growAfter && size < limit {
+			size, streak = min(size*2, limit), 0
+		}
+	}
+	return from, added, nil
+}
+
+// Reconcile 检查最近的检查点是否仍在规范链上。发生重组时把索引位置回退到最近一个仍然有效的检查点，
+// 没有有效检查点时回退到首次索引的起始区块，下次 Backfill 重新索引这些区块，
+// 并通过 OnEvent 报告不再存在的事件。返回是否发生了回退。
+func (ix *Indexer) Reconcile(ctx context.Context) (bool, error) {
+	cps, err := ix.store.Checkpoints(ix.chainID, ix.address)
+	if err != nil || len(cps) == 0 {
+		return false, err
+	}
+	for i, cp := range cps {
+		header, err := ix.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(cp.Block))
+		if errors.Is(err, ethereum.NotFound) {
+			continue // 新链比检查点短
+		}
+		if err != nil {
+			return false, err
+		}
+		if header.Hash() != cp.Hash {
+			continue
+		}
+		if i == 0 {
+			return false, nil
+		}
+		return true, ix.store.Rewind(ix.chainID, ix.address, cp.Block+1)
+	}
+	first, _, err := ix.store.First(ix.chainID, ix.address)
+	if err != nil {
+		return false, err
+	}
+	return true, ix.store.Rewind(ix.chainID, ix.address, first)
+}
+
+func (ix *Indexer) notify(events []Event, removed bool) {
+	if ix.OnEvent == nil {
+		return
+	}
+	for _, ev := range events {
+		ix.OnEvent(ev, removed)
+	}
+}
+
+func (ix *Indexer) filter(ctx context.Context, from, to uint64) ([]Event, error) {
+	it, err := ix.filterer.FilterIncrement(&bind.FilterOpts{Start: from, End: &to, Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var events []Event
+	for it.Next() {
+		events = append(events, newEvent(it.Event))
+	}
+	return events, it.Error()
+}
+
+func newEvent(ev *counter.CounterIncrement) Event {
+	return Event{
+		Block:     ev.Raw.BlockNumber,
+		BlockHash: ev.Raw.BlockHash,
+		TxHash:    ev.Raw.TxHash,
+		LogIndex:  ev.Raw.Index,
+		By:        ev.By,
+	}
+}
+
+// tooManyResults 各家节点对 eth_getLogs 结果或区块范围超限的报错片段
+var tooManyResults = []string{
+	"too many results",
+	"query returned more than",
+	"response size exceeded",
+	"response size should not",
+	"block range is too wide",
+	"range is too large",
+	"range too large",
+	"max block range",
+	"maximum block range",
+}
+
+// IsTooManyResults 判断 eth_getLogs 是否因结果过多或区块范围过大被拒绝，此时缩小区块范围重试即可。
+// 只看报错内容：-32005 也用于限流，没有上述片段的 -32005 应当等待后重试，而不是缩小范围。
+func IsTooManyResults(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range tooManyResults {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeploymentBlock 在 [0, latest] 内二分查找合约代码首次出现的区块。
+// 需要节点能查询历史状态（归档节点），否则应直接指定部署区块。
+func DeploymentBlock(ctx context.Context, backend CodeBackend, address common.Address, latest uint64) (uint64, error) {
+	hasCode := func(block uint64) (bool, error) {
+		code, err := backend.CodeAt(ctx, address, new(big.Int).SetUint64(block))
+		return len(code) > 0, err
+	}
+	ok, err := hasCode(latest)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("no contract code at %s in block %d", address.Hex(), latest)
+	}
+	lo, hi := uint64(0), latest
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		ok, err := hasCode(mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
@@ -0,0 +1,359 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+
+	"sepolia-block/counter"
+)
+
+// testEnv 模拟链、已部署的 Counter 和临时事件库
+type testEnv struct {
+	sim     *simulated.Backend
+	client  simulated.Client
+	auth    *bind.TransactOpts
+	chainID *big.Int
+	address common.Address
+	counter *counter.Counter
+	deploy  uint64 // 部署区块
+	store   *Store
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	balance := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	sim := simulated.NewBackend(types.GenesisAlloc{from: {Balance: balance}})
+	t.Cleanup(func() { sim.Close() })
+
+	client := sim.Client()
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, _, c, err := counter.DeployCounter(auth, client)
+	if err != nil {
+		t.Fatalf("DeployCounter: %v", err)
+	}
+	sim.Commit()
+	store, err := OpenStore(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	env := &testEnv{sim: sim, client: client, auth: auth, chainID: chainID, address: address, counter: c, store: store}
+	env.deploy = env.head(t)
+	return env
+}
+
+// incBy 发送 IncBy(by) 并出块，返回所在区块
+func (e *testEnv) incBy(t *testing.T, by int64) uint64 {
+	t.Helper()
+	if _, err := e.counter.IncBy(e.auth, big.NewInt(by)); err != nil {
+		t.Fatalf("IncBy: %v", err)
+	}
+	e.sim.Commit()
+	return e.head(t)
+}
+
+func (e *testEnv) head(t *testing.T) uint64 {
+	t.Helper()
+	head, err := e.client.BlockNumber(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return head
+}
+
+func (e *testEnv) indexer(filterer IncrementFilterer) *Indexer {
+	if filterer == nil {
+		filterer = e.counter
+	}
+	return New(filterer, e.client, e.store, e.chainID, e.address)
+}
+
+// stored 事件库中区块 to 及之前的事件
+func (e *testEnv) stored(t *testing.T, to uint64) []Event {
+	t.Helper()
+	events, err := e.store.Events(e.chainID, e.address, 0, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+// bys 事件库中全部事件的 By
+func (e *testEnv) bys(t *testing.T) []int64 {
+	t.Helper()
+	var bys []int64
+	for _, ev := range e.stored(t, e.head(t)) {
+		bys = append(bys, ev.By.Int64())
+	}
+	return bys
+}
+
+// limitedFilterer 模拟节点限制：查询超过 max 个区块时报告结果过多，fail 可让指定区块的查询失败
+type limitedFilterer struct {
+	*counter.Counter
+	max  uint64
+	fail uint64
+
+	mu     sync.Mutex
+	ranges [][2]uint64 // 成功的查询
+}
+
+func (f *limitedFilterer) FilterIncrement(opts *bind.FilterOpts) (*counter.CounterIncrementIterator, error) {
+	from, to := opts.Start, *opts.End
+	if f.max > 0 && to-from+1 > f.max {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	if f.fail != 0 && from <= f.fail && f.fail <= to {
+		return nil, errors.New("connection reset")
+	}
+	f.mu.Lock()
+	f.ranges = append(f.ranges, [2]uint64{from, to})
+	f.mu.Unlock()
+	return f.Counter.FilterIncrement(opts)
+}
+
+// rpcError 节点返回的 JSON-RPC 错误
+type rpcError struct {
+	code int
+	msg  string
+}
+
+func (e rpcError) Error() string  { return e.msg }
+func (e rpcError) ErrorCode() int { return e.code }
+
+func TestIsTooManyResults(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{rpcError{-32005, "query returned more than 10000 results"}, true},
+		{rpcError{-32602, "Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range"}, true},
+		{fmt.Errorf("filter: %w", rpcError{-32000, "block range is too wide"}), true},
+		// Infura 等节点的限流同样使用 -32005，应当等待重试而不是缩小范围
+		{rpcError{-32005, "limit exceeded"}, false},
+		{rpcError{-32005, "project ID request rate exceeded"}, false},
+		{errors.New("connection reset"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsTooManyResults(tt.err); got != tt.want {
+			t.Errorf("IsTooManyResults(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestBackfillShrinksChunk(t *testing.T) {
+	env := newTestEnv(t)
+	for by := range int64(10) {
+		env.incBy(t, by+1)
+		env.sim.Commit() // 空区块
+	}
+	head := env.head(t)
+
+	filterer := &limitedFilterer{Counter: env.counter, max: 3}
+	ix := env.indexer(filterer)
+	ix.ChunkSize = 8
+	var sizes []uint64
+	ix.OnShrink = func(size uint64, err error) {
+		if !IsTooManyResults(err) {
+			t.Errorf("OnShrink with %v, want a too-many-results error", err)
+		}
+		sizes = append(sizes, size)
+	}
+	from, added, err := ix.Backfill(context.Background(), env.deploy, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != env.deploy || added != 10 {
+		t.Errorf("Backfill = %d, %d, want %d, 10", from, added, env.deploy)
+	}
+	if len(sizes) < 2 || sizes[0] != 4 || sizes[1] != 2 {
+		t.Errorf("chunk sizes after shrinking %v, want to start with [4 2]", sizes)
+	}
+	// 成功的查询首尾相接覆盖全部区块
+	next := env.deploy
+	for _, r := range filterer.ranges {
+		if r[0] != next || r[1]-r[0]+1 > filterer.max {
+			t.Fatalf("queried blocks %v, want %d-%d at most", filterer.ranges, next, next+filterer.max-1)
+		}
+		next = r[1] + 1
+	}
+	if next != head+1 {
+		t.Errorf("queries ended at block %d, want %d", next-1, head)
+	}
+	if got := env.bys(t); len(got) != 10 || got[0] != 1 || got[9] != 10 {
+		t.Errorf("indexed events %v, want 1..10", got)
+	}
+}
+
+func TestBackfillResume(t *testing.T) {
+	env := newTestEnv(t)
+	var blocks []uint64
+	for by := range int64(6) {
+		blocks = append(blocks, env.incBy(t, by+1))
+	}
+	head := env.head(t)
+
+	// 第一次在第 4 个事件所在的区块中断
+	broken := &limitedFilterer{Counter: env.counter, fail: blocks[3]}
+	ix := env.indexer(broken)
+	ix.ChunkSize = 2
+	if _, _, err := ix.Backfill(context.Background(), env.deploy, head); err == nil {
+		t.Fatal("Backfill succeeded, want the injected error")
+	}
+	last, ok, err := env.store.Last(env.chainID, env.address)
+	if err != nil || !ok || last >= blocks[3] {
+		t.Fatalf("Last = %d, %v, %v, want a block before %d", last, ok, err, blocks[3])
+	}
+	before := len(env.stored(t, last))
+
+	// 新的 Indexer 从检查点之后继续，不重复查询和写入
+	filterer := &limitedFilterer{Counter: env.counter}
+	from, added, err := env.indexer(filterer).Backfill(context.Background(), env.deploy, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != last+1 || filterer.ranges[0][0] != last+1 {
+		t.Errorf("resumed from %d (first query %v), want %d", from, filterer.ranges[0], last+1)
+	}
+	if got := env.bys(t); len(got) != 6 || added != 6-before {
+		t.Errorf("indexed events %v, %d added on resume, want 6 in total and %d added", got, added, 6-before)
+	}
+}
+
+func TestReconcileFork(t *testing.T) {
+	env := newTestEnv(t)
+	env.incBy(t, 1)
+	forkPoint := env.incBy(t, 2)
+	parent, err := env.client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(forkPoint))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphaned := env.incBy(t, 3)
+
+	ix := env.indexer(nil)
+	ix.ChunkSize = 1 // 每个区块一个检查点
+	var removed []Event
+	ix.OnEvent = func(ev Event, gone bool) {
+		if gone {
+			removed = append(removed, ev)
+		}
+	}
+	if _, _, err := ix.Backfill(context.Background(), env.deploy, orphaned); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := ix.Reconcile(context.Background()); err != nil || changed {
+		t.Fatalf("Reconcile on an unchanged chain = %v, %v", changed, err)
+	}
+
+	// 从 forkPoint 分叉出更长的链，orphaned 中的 IncBy(3) 被撤销
+	if err := env.sim.Fork(parent.Hash()); err != nil {
+		t.Fatal(err)
+	}
+	env.sim.Commit()
+	env.sim.Commit()
+	if env.head(t) <= orphaned {
+		t.Fatalf("fork did not become canonical: head %d", env.head(t))
+	}
+	changed, err := ix.Reconcile(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Reconcile after the fork = %v, %v, want a rewind", changed, err)
+	}
+	if last, _, _ := env.store.Last(env.chainID, env.address); last != forkPoint {
+		t.Errorf("rewound to block %d, want the fork point %d", last, forkPoint)
+	}
+	if _, _, err := ix.Backfill(context.Background(), env.deploy, env.head(t)); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) == 0 || removed[0].Block != orphaned || removed[0].By.Int64() != 3 {
+		t.Errorf("removed events %+v, want IncBy(3) from block %d", removed, orphaned)
+	}
+
+	// 索引与新链上的事件一致
+	it, err := env.counter.FilterIncrement(&bind.FilterOpts{Start: env.deploy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var want []Event
+	for it.Next() {
+		want = append(want, newEvent(it.Event))
+	}
+	got := env.stored(t, env.head(t))
+	if len(got) != len(want) {
+		t.Fatalf("indexed %d events, canonical chain has %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].BlockHash != want[i].BlockHash || got[i].TxHash != want[i].TxHash || got[i].By.Cmp(want[i].By) != 0 {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFollower(t *testing.T) {
+	for _, poll := range []bool{false, true} {
+		env := newTestEnv(t)
+		env.incBy(t, 1)
+
+		f := NewFollower(env.indexer(nil), env.counter)
+		f.Poll = poll
+		f.PollInterval = 20 * time.Millisecond
+		events := make(chan Event, 16)
+		f.OnEvent = func(ev Event, removed bool) {
+			if !removed {
+				events <- ev
+			}
+		}
+		connected := make(chan bool, 1)
+		f.OnConnect = func(subscribed bool) { connected <- subscribed }
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- f.Run(ctx, env.deploy) }()
+
+		if subscribed := <-connected; subscribed == poll {
+			t.Errorf("poll %v: subscribed %v", poll, subscribed)
+		}
+		env.incBy(t, 2)
+		var bys []int64
+		timeout := time.After(10 * time.Second)
+		for len(bys) < 2 {
+			select {
+			case ev := <-events:
+				bys = append(bys, ev.By.Int64())
+			case <-timeout:
+				t.Fatalf("poll %v: received %v, want [1 2]", poll, bys)
+			}
+		}
+		if bys[0] != 1 || bys[1] != 2 {
+			t.Errorf("poll %v: received %v, want [1 2]", poll, bys)
+		}
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("poll %v: Run = %v, want context.Canceled", poll, err)
+		}
+	}
+}
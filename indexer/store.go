@@ -0,0 +1,354 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Event 已索引的 Increment 事件
+type Event struct {
+	Block     uint64      `json:"block"`
+	BlockHash common.Hash `json:"block_hash"`
+	TxHash    common.Hash `json:"tx_hash"`
+	LogIndex  uint        `json:"log_index"`
+	By        *big.Int    `json:"by"`
+}
+
+// Checkpoint 已索引区块的哈希，用于检测重组
+type Checkpoint struct {
+	Block uint64
+	Hash  common.Hash
+}
+
+// CheckpointDepth 保留检查点的区块数：只保留最近 CheckpointDepth 个区块内的检查点，
+// 以及更早的最新一个。重组深于此时 Reconcile 回退到那个较早的检查点。
+const CheckpointDepth = 128
+
+var (
+	eventsBucket      = []byte("events")
+	checkpointsBucket = []byte("checkpoints")
+	firstKey          = []byte("first")
+	lastKey           = []byte("last")
+)
+
+// Store 基于 bbolt 的本地事件库。每个 (链 ID, 合约) 一个 bucket，
+// 事件按 (区块号, 日志序号) 排序存放，另记录已索引到的最后一个区块，
+// 以及每次写入时区间末尾区块的哈希（检查点），检查点按 CheckpointDepth 清理。
+type Store struct {
+	db *bolt.DB
+}
+
+// OpenStore 打开（必要时创建）path 处的事件库。
+// bbolt 对文件加排他锁，同一时间只能有一个进程打开。
+func OpenStore(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open index %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close 关闭事件库
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func contractKey(chainID *big.Int, address common.Address) []byte {
+	return []byte(chainID.String() + "/" + address.Hex())
+}
+
+func eventKey(block uint64, logIndex uint) []byte {
+	key := make([]byte, 12)
+	binary.BigEndian.PutUint64(key, block)
+	binary.BigEndian.PutUint32(key[8:], uint32(logIndex))
+	return key
+}
+
+func blockKey(block uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, block)
+}
+
+// view 在只读事务中访问合约的 bucket，尚未索引过时 b 为 nil
+func (s *Store) view(chainID *big.Int, address common.Address, fn func(b *bolt.Bucket) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(contractKey(chainID, address)))
+	})
+}
+
+// update 在读写事务中访问合约的 bucket 及其 events、checkpoints 子 bucket，不存在时创建
+func (s *Store) update(chainID *big.Int, address common.Address, fn func(b, events, checkpoints *bolt.Bucket) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(contractKey(chainID, address))
+		if err != nil {
+			return err
+		}
+		events, err := b.CreateBucketIfNotExists(eventsBucket)
+		if err != nil {
+			return err
+		}
+		checkpoints, err := b.CreateBucketIfNotExists(checkpointsBucket)
+		if err != nil {
+			return err
+		}
+		return fn(b, events, checkpoints)
+	})
+}
+
+// Last 返回已索引到的最后一个区块，尚未索引过时 ok 为 false
+func (s *Store) Last(chainID *big.Int, address common.Address) (last uint64, ok bool, err error) {
+	err = s.view(chainID, address, func(b *bolt.Bucket) error {
+		if b == nil {
+			return nil
+		}
+		if v := b.Get(lastKey); v != nil {
+			last, ok = binary.BigEndian.Uint64(v), true
+		}
+		return nil
+	})
+	return last, ok, err
+}
+
+// First 返回首次索引的起始区块，尚未索引过时 ok 为 false
+func (s *Store) First(chainID *big.Int, address common.Address) (first uint64, ok bool, err error) {
+	err = s.view(chainID, address, func(b *bolt.Bucket) error {
+		if b == nil {
+			return nil
+		}
+		if v := b.Get(firstKey); v != nil {
+			first, ok = binary.BigEndian.Uint64(v), true
+		}
+		return nil
+	})
+	return first, ok, err
+}
+
+// Checkpoints 按区块号从新到旧返回检查点
+func (s *Store) Checkpoints(chainID *big.Int, address common.Address) ([]Checkpoint, error) {
+	var cps []Checkpoint
+	err := s.view(chainID, address, func(b *bolt.Bucket) error {
+		if b == nil || b.Bucket(checkpointsBucket) == nil {
+			return nil
+		}
+		c := b.Bucket(checkpointsBucket).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			cps = append(cps, Checkpoint{Block: binary.BigEndian.Uint64(k), Hash: common.BytesToHash(v)})
+		}
+		return nil
+	})
+	return cps, err
+}
+
+// Append 以 events 作为区块 [from, to] 内的全部事件写入，区间内原有但不在 events 中的事件被删除，
+// 然后把已索引位置推进到 to，并记录 to 的区块哈希 hash。这些在同一事务中完成，
+// 中途退出后可以从 Last()+1 继续而不会漏掉或重复事件。已有索引时 from 必须紧接在已索引位置之后。
+// 返回新写入和被删除的事件，已存在且相同的事件不算新写入。
+func (s *Store) Append(chainID *big.Int, address common.Address, from, to uint64, hash common.Hash, events []Event) (added, removed []Event, err error) {
+	err = s.update(chainID, address, func(b, eb, cb *bolt.Bucket) error {
+		if v := b.Get(lastKey); v != nil {
+			if last := binary.BigEndian.Uint64(v); from != last+1 {
+				return fmt.Errorf("index gap: last indexed block %d, appending from %d", last, from)
+			}
+		} else if v := b.Get(firstKey); v == nil {
+			if err := b.Put(firstKey, blockKey(from)); err != nil {
+				return err
+			}
+		} else if first := binary.BigEndian.Uint64(v); from != first {
+			return fmt.Errorf("index gap: index starts at block %d, appending from %d", first, from)
+		}
+		keep := make(map[string]Event, len(events))
+		for _, ev := range events {
+			if ev.Block < from || ev.Block > to {
+				return fmt.Errorf("event in block %d outside range %d-%d", ev.Block, from, to)
+			}
+			keep[string(eventKey(ev.Block, ev.LogIndex))] = ev
+		}
+		// 同一位置换成了其他区块或交易的事件，也视为旧事件被删除
+		stale, err := deleteRange(eb, from, to, func(k []byte, old Event) bool {
+			ev, ok := keep[string(k)]
+			return !ok || ev.BlockHash != old.BlockHash || ev.TxHash != old.TxHash
+		})
+		if err != nil {
+			return err
+		}
+		removed = stale
+		for _, ev := range events {
+			ok, err := put(eb, ev)
+			if err != nil {
+				return err
+			}
+			if ok {
+				added = append(added, ev)
+			}
+		}
+		if err := cb.Put(blockKey(to), hash.Bytes()); err != nil {
+			return err
+		}
+		if err := pruneCheckpoints(cb, to); err != nil {
+			return err
+		}
+		return b.Put(lastKey, blockKey(to))
+	})
+	return added, removed, err
+}
+
+// Put 写入单个事件（例如订阅收到的事件），不改变已索引位置。
+// 同一位置已有相同事件时返回 false。
+func (s *Store) Put(chainID *big.Int, address common.Address, ev Event) (bool, error) {
+	var ok bool
+	err := s.update(chainID, address, func(_, eb, _ *bolt.Bucket) (err error) {
+		ok, err = put(eb, ev)
+		return err
+	})
+	return ok, err
+}
+
+// Remove 删除被重组撤销的事件，只有库中同一位置的事件来自同一区块时才删除
+func (s *Store) Remove(chainID *big.Int, address common.Address, ev Event) (bool, error) {
+	var ok bool
+	err := s.update(chainID, address, func(_, eb, _ *bolt.Bucket) error {
+		key := eventKey(ev.Block, ev.LogIndex)
+		v := eb.Get(key)
+		if v == nil {
+			return nil
+		}
+		var stored Event
+		if err := json.Unmarshal(v, &stored); err != nil {
+			return err
+		}
+		if stored.BlockHash != ev.BlockHash {
+			return nil
+		}
+		ok = true
+		return eb.Delete(key)
+	})
+	return ok, err
+}
+
+// Rewind 把已索引位置回退到 block-1，删除 block 及之后的检查点。
+// 事件暂时保留，重新索引这些区块时由 Append 逐段核对，只删除不再存在的事件。
+// block 不晚于首次索引的起始区块时回退到尚未索引的状态，之后从起始区块重新索引。
+func (s *Store) Rewind(chainID *big.Int, address common.Address, block uint64) error {
+	return s.update(chainID, address, func(b, _, cb *bolt.Bucket) error {
+		c := cb.Cursor()
+		for k, _ := c.Seek(blockKey(block)); k != nil; k, _ = c.Seek(blockKey(block)) {
+			if err := cb.Delete(k); err != nil {
+				return err
+			}
+		}
+		if v := b.Get(firstKey); v == nil || block <= binary.BigEndian.Uint64(v) {
+			return b.Delete(lastKey)
+		}
+		return b.Put(lastKey, blockKey(block-1))
+	})
+}
+
+// Events 按顺序返回区块 [from, to] 内已索引的事件
+func (s *Store) Events(chainID *big.Int, address common.Address, from, to uint64) ([]Event, error) {
+	var events []Event
+	err := s.view(chainID, address, func(b *bolt.Bucket) error {
+		if b == nil || b.Bucket(eventsBucket) == nil {
+			return nil
+		}
+		return scan(b.Bucket(eventsBucket), from, to, func(_ []byte, ev Event) error {
+			events = append(events, ev)
+			return nil
+		})
+	})
+	return events, err
+}
+
+// pruneCheckpoints 删除 to 之前 CheckpointDepth 个区块以外的检查点，只保留其中最新的一个
+func pruneCheckpoints(cb *bolt.Bucket, to uint64) error {
+	if to < CheckpointDepth {
+		return nil
+	}
+	c := cb.Cursor()
+	// 定位到窗口外最新的检查点，它之前的全部删除
+	k, _ := c.Seek(blockKey(to - CheckpointDepth + 1))
+	if k == nil {
+		k, _ = c.Last()
+	} else {
+		k, _ = c.Prev()
+	}
+	if k == nil {
+		return nil
+	}
+	var keys [][]byte
+	for k, _ = c.Prev(); k != nil; k, _ = c.Prev() {
+		keys = append(keys, bytes.Clone(k))
+	}
+	for _, k := range keys {
+		if err := cb.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// put 写入事件，同一位置已有相同内容时返回 false
+func put(eb *bolt.Bucket, ev Event) (bool, error) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return false, err
+	}
+	key := eventKey(ev.Block, ev.LogIndex)
+	if bytes.Equal(eb.Get(key), data) {
+		return false, nil
+	}
+	return true, eb.Put(key, data)
+}
+
+// scan 按顺序遍历区块 [from, to] 内的事件
+func scan(eb *bolt.Bucket, from, to uint64, fn func(k []byte, ev Event) error) error {
+	c := eb.Cursor()
+	for k, v := c.Seek(eventKey(from, 0)); k != nil; k, v = c.Next() {
+		if binary.BigEndian.Uint64(k) > to {
+			break
+		}
+		var ev Event
+		if err := json.Unmarshal(v, &ev); err != nil {
+			return err
+		}
+		if err := fn(k, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteRange 删除区块 [from, to] 内满足 match 的事件并返回它们
+func deleteRange(eb *bolt.Bucket, from, to uint64, match func(k []byte, ev Event) bool) ([]Event, error) {
+	var (
+		keys    [][]byte
+		deleted []Event
+	)
+	err := scan(eb, from, to, func(k []byte, ev Event) error {
+		if match(k, ev) {
+			keys = append(keys, bytes.Clone(k))
+			deleted = append(deleted, ev)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 遍历时不能删除，统一在之后删除
+	for _, k := range keys {
+		if err := eb.Delete(k); err != nil {
+			return nil, err
+		}
+	}
+	return deleted, nil
+}
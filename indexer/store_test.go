@@ -0,0 +1,39 @@
+package indexer
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCheckpointsPruned(t *testing.T) {
+	store, err := OpenStore(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	chainID, address := big.NewInt(1337), common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+
+	// 每 10 个区块一个检查点：9, 19, ..., 999
+	for from := uint64(0); from < 1000; from += 10 {
+		hash := common.BigToHash(new(big.Int).SetUint64(from + 9))
+		if _, _, err := store.Append(chainID, address, from, from+9, hash, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cps, err := store.Checkpoints(chainID, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 窗口 [872, 999] 内的 879..999 共 13 个，加上窗口外最新的 869
+	if len(cps) != 14 || cps[0].Block != 999 || cps[len(cps)-1].Block != 869 {
+		t.Fatalf("kept %d checkpoints from %d down to %d, want 14 from 999 down to 869", len(cps), cps[0].Block, cps[len(cps)-1].Block)
+	}
+	for _, cp := range cps {
+		if cp.Hash != common.BigToHash(new(big.Int).SetUint64(cp.Block)) {
+			t.Errorf("checkpoint %d has hash %s", cp.Block, cp.Hash)
+		}
+	}
+}
@@ -1,156 +1,138 @@
 package main
 
 import (
-	"context"
-	"crypto/ecdsa"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
-	"math/big"
 	"os"
+	"sort"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 
-	"sepolia-block/counter"
+	"sepolia-block/cassette"
+	"sepolia-block/chain"
+	"sepolia-block/deploy"
+	"sepolia-block/i18n"
+	"sepolia-block/revert"
+	"sepolia-block/txmgr"
 )
 
-func blockTest() {
-	// 连接测试节点main
-	client, err := ethclient.Dial("https://1rpc.io/sepolia")
-	if err != nil {
-		log.Fatalf("连接失败： %v", err)
-	}
-	defer client.Close() // 关闭
-
-	// 指定区块号
-	blockNumber := big.NewInt(1898989)
-
-	// 区块信息main
-	block, err := client.BlockByNumber(context.Background(), blockNumber)
-	if err != nil {
-		log.Fatalf("区块获取失败：%v", err)
-	}
+// command 子命令入口，args 不含命令名本身
+type command struct {
+	usage i18n.Key
+	run   func(args []string) error
+}
 
-	// 输出区块信息
-	fmt.Printf("区块号: %d\n", block.NumberU64())
-	fmt.Printf("区块哈希: %s\n", block.Hash().Hex())
-	fmt.Printf("时间戳: %d\n", block.Time())
-	fmt.Printf("交易数量: %d\n", len(block.Transactions()))
+var commands = map[string]command{
+	"block":    {i18n.CmdBlock, runBlock},
+	"transfer": {i18n.CmdTransfer, runTransfer},
+	"counter":  {i18n.CmdCounter, runCounter},
+	"tx":       {i18n.CmdTx, runTx},
+}
 
-	privateKeyHex := os.Getenv("private_key")
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		log.Fatal(err)
-	}
+// msgs 当前语言的消息，main 在解析参数前按 -lang 或 LANG 设置
+var msgs = i18n.NewPrinter(i18n.Default)
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA := publicKey.(*ecdsa.PublicKey)
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-	// 查询 nonce
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+func main() {
+	log.SetFlags(0)
+	lang, err := detectLang(os.Args[1:])
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	// 设置转账参数
-	toAddress := common.HexToAddress("0xEfDA589312a37aB1b0cac1f11d5b96117D31bCF9")
-	value := big.NewInt(1e14) // 0.0001 ETH (1e18 = 1 ETH)
-	gasLimit := uint64(21000)
-
-	gasPrice, err := client.SuggestGasPrice(context.Background())
-	if err != nil {
-		log.Fatal(err)
+	msgs = i18n.NewPrinter(lang)
+	if err := run(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		log.Fatal(localizeError(err))
 	}
+}
 
-	// 构造交易
-	tx := types.NewTransaction(
-		nonce,
-		toAddress,
-		value,
-		gasLimit,
-		gasPrice,
-		nil,
-	) //
-
-	// 获取链 ID（Sepolia = 11155111）
-	chainID, err := client.NetworkID(context.Background())
-	if err != nil {
-		log.Fatal(err)
-	}
+// closers 命令结束后需要关闭的资源，例如 -record 的 cassette 文件
+var closers []io.Closer
+
+// run 执行子命令，结束后按打开的逆序关闭 closers。
+// main 出错时以 log.Fatal 退出，不会执行 defer，因此在这里而不是 main 中清理。
+func run(args []string) (err error) {
+	defer func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			err = errors.Join(err, closers[i].Close())
+		}
+		closers = nil
+	}()
+	return dispatch("sepolia-block", commands, args)
+}
 
-	// 签名交易
-	signedTx, err := types.SignTx(
-		tx,
-		types.NewEIP155Signer(chainID),
-		privateKey,
+// localizeError 把其他包返回的已知错误换成当前语言的消息
+func localizeError(err error) string {
+	var (
+		mismatch *chain.MismatchError
+		gasCap   *txmgr.GasCapError
+		sim      *txmgr.SimulationError
+		reverted *txmgr.RevertedError
+		reason   *revert.ReasonError
+		panicErr *revert.PanicError
+		custom   *revert.CustomError
+		unknown  *revert.UnknownError
 	)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// 发送交易
-	err = client.SendTransaction(context.Background(), signedTx)
-	if err != nil {
-		log.Fatal(err)
+	switch {
+	case errors.As(err, &sim):
+		return msgs.Sprintf(i18n.ErrSimulation, localizeError(sim.Err))
+	case errors.As(err, &mismatch):
+		return msgs.Sprintf(i18n.ErrChainMismatch, mismatch.Expected, mismatch.Actual)
+	case errors.As(err, &gasCap):
+		return msgs.Sprintf(i18n.ErrGasCap, gasCap.Estimated, gasCap.Cap)
+	case errors.As(err, &reverted):
+		return msgs.Sprintf(i18n.ErrReverted, reverted.Receipt.TxHash.Hex(), reverted.Receipt.BlockNumber)
+	case errors.Is(err, txmgr.ErrPriceBumpTooLow):
+		return msgs.Sprintf(i18n.ErrPriceBumpTooLow)
+	case errors.Is(err, deploy.ErrStaleManifest):
+		return msgs.Sprintf(i18n.ErrStaleManifest, err)
+	case errors.Is(err, cassette.ErrNotRecorded):
+		return msgs.Sprintf(i18n.ErrNotRecorded, err)
+	case errors.As(err, &reason):
+		return msgs.Sprintf(i18n.ErrRevertReason, reason.Reason)
+	case errors.As(err, &panicErr):
+		if desc := panicErr.Description(); desc != "" {
+			return msgs.Sprintf(i18n.ErrRevertPanic, panicErr.Code, desc)
+		}
+		return msgs.Sprintf(i18n.ErrRevertPanicCode, panicErr.Code)
+	case errors.As(err, &custom):
+		return msgs.Sprintf(i18n.ErrRevertCustom, custom.Call())
+	case errors.As(err, &unknown):
+		if len(unknown.Data) == 0 {
+			return msgs.Sprintf(i18n.ErrRevertNoReason)
+		}
+		return msgs.Sprintf(i18n.ErrRevertUnknown, hexutil.Encode(unknown.Data))
 	}
-
-	// 输出交易哈希
-	fmt.Printf("交易已发送 🎉\nTx Hash: %s\n", signedTx.Hash().Hex())
-
+	return err.Error()
 }
 
-func main() {
-	// forge build 某合约, 生成json
-	// jq '.abi' out/Counter.sol/Counter.json > Counter.abi
-	// jq -r '.bytecode.object' out/Counter.sol/Counter.json > Counter.bin
-	// abigen \ --abi build/Counter.abi \ --bin build/Counter.bin \ --pkg counter \ --out counter.go
-
-	client, err := ethclient.Dial("https://1rpc.io/sepolia")
-	if err != nil {
-		log.Fatalf("连接失败： %v", err)
-	}
-	defer client.Close() // 关闭
-
-	privateKeyHex := os.Getenv("private_key")
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	auth, err := bind.NewKeyedTransactorWithChainID(
-		privateKey,
-		big.NewInt(11155111), // Sepolia chainID
-	)
-	if err != nil {
-		log.Fatal(err)
+// dispatch 根据第一个参数选择子命令
+func dispatch(prog string, cmds map[string]command, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+		printUsage(prog, cmds)
+		return flag.ErrHelp
 	}
-
-	// 合约地址已部署
-	contractAddress := common.HexToAddress("0xe09d7Ce1107Dc37C9c20d8019DD1786Ca82F6640")
-	c, err := counter.NewCounter(contractAddress, client)
-	if err != nil {
-		log.Fatal(err)
+	cmd, ok := cmds[args[0]]
+	if !ok {
+		printUsage(prog, cmds)
+		return msgs.Errorf(i18n.ErrUnknownCommand, args[0])
 	}
+	return cmd.run(args[1:])
+}
 
-	// 调用 inc() 修改状态
-	tx, err := c.Inc(auth)
-	if err != nil {
-		log.Fatal(err)
+func printUsage(prog string, cmds map[string]command) {
+	names := make([]string, 0, len(cmds))
+	for name := range cmds {
+		names = append(names, name)
 	}
-	fmt.Println("Increment transaction sent:", tx.Hash().Hex())
-
-	// 调用自动生成 get() 读取当前计数
-	num, err := c.X(&bind.CallOpts{
-		Pending: true,
-		From:    auth.From,
-		Context: context.Background(),
-	})
-	if err != nil {
-		log.Fatal(err)
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.UsageHeader, prog))
+	fmt.Fprintln(os.Stderr, "\n"+msgs.Sprintf(i18n.UsageCommands))
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, msgs.Sprintf(cmds[name].usage))
 	}
-
-	fmt.Println("Current counter value:", num)
 }
@@ -0,0 +1,161 @@
+// Package output 把命令结果按 text、JSON、NDJSON 或 CSV 格式输出。
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Format 输出格式
+type Format string
+
+const (
+	Text   Format = "text"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson" // 每行一个 JSON 对象，便于流式处理
+	CSV    Format = "csv"
+)
+
+// Formats 支持的全部格式
+var Formats = []Format{Text, JSON, NDJSON, CSV}
+
+// ParseFormat 解析格式名
+func ParseFormat(s string) (Format, error) {
+	if f := Format(s); slices.Contains(Formats, f) {
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown output format %q (available: %v)", s, Formats)
+}
+
+// Record 一条可输出的结果。JSON 格式直接序列化 Record 本身，
+// CSV 使用 Columns/Values，text 使用 Lines。
+type Record interface {
+	Columns() []string
+	Values() []string
+	Lines() []string
+}
+
+// Writer 按格式输出 Record
+type Writer struct {
+	w      io.Writer
+	format Format
+
+	streamed bool // Stream 已输出过记录（CSV 表头只输出一次）
+}
+
+// NewWriter 创建 Writer
+func NewWriter(w io.Writer, format Format) *Writer {
+	return &Writer{w: w, format: format}
+}
+
+// Write 输出单条结果；JSON 格式下是一个对象
+func (w *Writer) Write(rec Record) error {
+	if w.format == JSON {
+		return w.json(rec)
+	}
+	return w.list([]Record{rec})
+}
+
+// WriteList 输出一组结果；JSON 格式下是一个数组，空列表输出 []
+func (w *Writer) WriteList(recs []Record) error {
+	if w.format == JSON {
+		if recs == nil {
+			recs = []Record{}
+		}
+		return w.json(recs)
+	}
+	return w.list(recs)
+}
+
+// Stream 供持续输出的命令逐条输出：JSON 与 NDJSON 相同，每行一个对象；
+// CSV 只在第一条之前输出表头
+func (w *Writer) Stream(rec Record) error {
+	first := !w.streamed
+	w.streamed = true
+	switch w.format {
+	case JSON, NDJSON:
+		return json.NewEncoder(w.w).Encode(rec)
+	case CSV:
+		cw := csv.NewWriter(w.w)
+		if first {
+			if err := cw.Write(rec.Columns()); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(rec.Values()); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	case Text:
+		// 与 WriteList 一致，多行记录之间空一行
+		if !first && len(rec.Lines()) > 1 {
+			if _, err := fmt.Fprintln(w.w); err != nil {
+				return err
+			}
+		}
+	}
+	return w.list([]Record{rec})
+}
+
+func (w *Writer) json(v any) error {
+	enc := json.NewEncoder(w.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (w *Writer) list(recs []Record) error {
+	switch w.format {
+	case NDJSON:
+		enc := json.NewEncoder(w.w)
+		for _, rec := range recs {
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		if len(recs) == 0 {
+			return nil
+		}
+		cw := csv.NewWriter(w.w)
+		if err := cw.Write(recs[0].Columns()); err != nil {
+			return err
+		}
+		for _, rec := range recs {
+			if err := cw.Write(rec.Values()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case Text:
+		for i, rec := range recs {
+			lines := rec.Lines()
+			// 多行记录之间空一行
+			if i > 0 && len(lines) > 1 {
+				if _, err := fmt.Fprintln(w.w); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintln(w.w, strings.Join(lines, "\n")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q", w.format)
+}
+
+// List 把具体类型的切片转换为 []Record
+func List[T Record](recs []T) []Record {
+	out := make([]Record, len(recs))
+	for i, rec := range recs {
+		out[i] = rec
+	}
+	return out
+}
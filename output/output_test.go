@@ -0,0 +1,119 @@
+package output
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+// row 测试用的记录，note 非空时 text 格式输出两行
+type row struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+	note  string
+}
+
+func (r row) Columns() []string { return []string{"name", "value"} }
+func (r row) Values() []string  { return []string{r.Name, strconv.Itoa(r.Value)} }
+func (r row) Lines() []string {
+	lines := []string{r.Name + " = " + strconv.Itoa(r.Value)}
+	if r.note != "" {
+		lines = append(lines, "  "+r.note)
+	}
+	return lines
+}
+
+var (
+	a = row{Name: "a", Value: 1}
+	b = row{Name: "b", Value: 2}
+	// 多行记录
+	c = row{Name: "c", Value: 3, note: "first"}
+	d = row{Name: "d", Value: 4, note: "second"}
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{Text, "a = 1\n"},
+		{JSON, "{\n  \"name\": \"a\",\n  \"value\": 1\n}\n"},
+		{NDJSON, "{\"name\":\"a\",\"value\":1}\n"},
+		{CSV, "name,value\na,1\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := NewWriter(&buf, tt.format).Write(a); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: Write = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestWriteList(t *testing.T) {
+	tests := []struct {
+		format Format
+		recs   []Record
+		want   string
+	}{
+		{Text, List([]row{a, b}), "a = 1\nb = 2\n"},
+		{Text, List([]row{c, d}), "c = 3\n  first\n\nd = 4\n  second\n"},
+		{Text, nil, ""},
+		{JSON, List([]row{a, b}), "[\n  {\n    \"name\": \"a\",\n    \"value\": 1\n  },\n  {\n    \"name\": \"b\",\n    \"value\": 2\n  }\n]\n"},
+		{JSON, nil, "[]\n"},
+		{JSON, List([]row{}), "[]\n"},
+		{NDJSON, List([]row{a, b}), "{\"name\":\"a\",\"value\":1}\n{\"name\":\"b\",\"value\":2}\n"},
+		{NDJSON, nil, ""},
+		{CSV, List([]row{a, b}), "name,value\na,1\nb,2\n"},
+		{CSV, nil, ""},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := NewWriter(&buf, tt.format).WriteList(tt.recs); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: WriteList(%d records) = %q, want %q", tt.format, len(tt.recs), buf.String(), tt.want)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		format Format
+		recs   []row
+		want   string
+	}{
+		{Text, []row{a, b}, "a = 1\nb = 2\n"},
+		{Text, []row{c, d}, "c = 3\n  first\n\nd = 4\n  second\n"},
+		{JSON, []row{a, b}, "{\"name\":\"a\",\"value\":1}\n{\"name\":\"b\",\"value\":2}\n"},
+		{NDJSON, []row{a, b}, "{\"name\":\"a\",\"value\":1}\n{\"name\":\"b\",\"value\":2}\n"},
+		// 表头只在第一条之前输出一次
+		{CSV, []row{a, b, c}, "name,value\na,1\nb,2\nc,3\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, tt.format)
+		for _, rec := range tt.recs {
+			if err := w.Stream(rec); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: Stream = %q, want %q", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range Formats {
+		if got, err := ParseFormat(string(f)); err != nil || got != f {
+			t.Errorf("ParseFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("ParseFormat(\"yaml\") succeeded, want an error")
+	}
+}
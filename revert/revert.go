@@ -0,0 +1,296 @@
+// Package revert 从 eth_call、eth_estimateGas 的错误中提取回滚数据，
+// 解码为 Error(string)、Panic(uint256) 或合约 ABI 中声明的自定义错误，
+// 调用方可以用 errors.As 判断具体原因。
+package revert
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// Panic 码，见 Solidity 文档 “Panic via assert and Error via require”
+const (
+	PanicGeneric         = 0x00
+	PanicAssert          = 0x01
+	PanicOverflow        = 0x11
+	PanicDivisionByZero  = 0x12
+	PanicEnumConversion  = 0x21
+	PanicStorageEncoding = 0x22
+	PanicEmptyPop        = 0x31
+	PanicOutOfBounds     = 0x32
+	PanicOutOfMemory     = 0x41
+	PanicZeroFunction    = 0x51
+)
+
+var panicReasons = map[uint64]string{
+	PanicGeneric:         "generic panic",
+	PanicAssert:          "assert(false)",
+	PanicOverflow:        "arithmetic underflow or overflow",
+	PanicDivisionByZero:  "division or modulo by zero",
+	PanicEnumConversion:  "enum overflow",
+	PanicStorageEncoding: "invalid encoded storage byte array accessed",
+	PanicEmptyPop:        "pop on an empty array",
+	PanicOutOfBounds:     "out-of-bounds array access",
+	PanicOutOfMemory:     "out of memory",
+	PanicZeroFunction:    "uninitialized function",
+}
+
+// ReasonError require/revert 给出的字符串原因，即 Error(string)
+type ReasonError struct {
+	Reason string
+	Err    error // 节点返回的原始错误
+}
+
+func (e *ReasonError) Error() string {
+	return "execution reverted: " + e.Reason
+}
+
+func (e *ReasonError) Unwrap() error { return e.Err }
+
+// PanicError 编译器插入的检查失败，即 Panic(uint256)，例如 inc() 溢出时为 0x11
+type PanicError struct {
+	Code *big.Int
+	Err  error
+}
+
+// Description 返回 panic 码的含义，未知的码返回空串
+func (e *PanicError) Description() string {
+	if !e.Code.IsUint64() {
+		return ""
+	}
+	return panicReasons[e.Code.Uint64()]
+}
+
+func (e *PanicError) Error() string {
+	if desc := e.Description(); desc != "" {
+		return fmt.Sprintf("execution reverted: panic %#x (%s)", e.Code, desc)
+	}
+	return fmt.Sprintf("execution reverted: panic %#x", e.Code)
+}
+
+func (e *PanicError) Unwrap() error { return e.Err }
+
+// CustomError 合约 ABI 中声明的自定义错误
+type CustomError struct {
+	ABI  abi.Error
+	Args []any // 按声明顺序解码的参数
+	Err  error
+}
+
+// Name 错误名
+func (e *CustomError) Name() string {
+	return e.ABI.Name
+}
+
+// Call 以 Name(arg1, arg2) 的形式返回错误及其参数
+func (e *CustomError) Call() string {
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = fmt.Sprint(arg)
+	}
+	return e.ABI.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (e *CustomError) Error() string {
+	return "execution reverted: " + e.Call()
+}
+
+func (e *CustomError) Unwrap() error { return e.Err }
+
+// UnknownError 回滚数据为空（不带原因的 revert()）或无法识别
+type UnknownError struct {
+	Data []byte
+	Err  error
+}
+
+func (e *UnknownError) Error() string {
+	if len(e.Data) == 0 {
+		return "execution reverted"
+	}
+	return "execution reverted: unknown error " + hexutil.Encode(e.Data)
+}
+
+func (e *UnknownError) Unwrap() error { return e.Err }
+
+// Decoder 解码回滚数据。零值只识别 Error(string) 和 Panic(uint256)。
+type Decoder struct {
+	custom map[[4]byte]abi.Error
+}
+
+// NewDecoder 创建 Decoder，同时识别 metas 中声明的自定义错误，例如 counter.CounterMetaData
+func NewDecoder(metas ...*bind.MetaData) (*Decoder, error) {
+	d := &Decoder{custom: make(map[[4]byte]abi.Error)}
+	for _, meta := range metas {
+		parsed, err := meta.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range parsed.Errors {
+			d.custom[[4]byte(e.ID[:4])] = e
+		}
+	}
+	return d, nil
+}
+
+// Data 从节点错误中提取回滚数据。ok 表示错误是一次回滚，此时 data 可能为空。
+func Data(err error) (data []byte, ok bool) {
+	if !isRevert(err) {
+		return nil, false
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if raw, ok := dataString(dataErr.ErrorData()); ok {
+			if data, err := hexutil.Decode(raw); err == nil {
+				return data, true
+			}
+		}
+	}
+	return nil, true
+}
+
+// isRevert 判断是否为执行回滚：先看 JSON-RPC 错误，error.data 带回滚数据或错误码为 3（geth）即是回滚；
+// 其余情况只认 "execution reverted" 等明确的说法，不匹配任意含 revert 的消息
+func isRevert(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if _, ok := dataString(dataErr.ErrorData()); ok {
+			return true
+		}
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == 3 {
+		return true
+	}
+	// 部分节点用 -32000 等通用错误码报告不带数据的回滚
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "execution reverted") || strings.Contains(msg, "vm exception")
+}
+
+// dataString 取出 error.data 中的十六进制串，兼容直接给出字符串和 {"data": "0x…"} 两种格式
+func dataString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, strings.HasPrefix(v, "0x")
+	case map[string]any:
+		if s, ok := v["data"].(string); ok {
+			return dataString(s)
+		}
+	}
+	return "", false
+}
+
+// Decode 把回滚错误转换为 *ReasonError、*PanicError、*CustomError 或 *UnknownError，
+// 其他错误原样返回。转换后的错误通过 Unwrap 保留原始错误。
+func (d *Decoder) Decode(err error) error {
+	if err == nil {
+		return nil
+	}
+	if isDecoded(err) {
+		return err // 已经解码过（例如多层包装）
+	}
+	data, ok := Data(err)
+	if !ok {
+		return err
+	}
+	return d.decode(data, err)
+}
+
+func isDecoded(err error) bool {
+	var (
+		reason  *ReasonError
+		panics  *PanicError
+		custom  *CustomError
+		unknown *UnknownError
+	)
+	return errors.As(err, &reason) || errors.As(err, &panics) || errors.As(err, &custom) || errors.As(err, &unknown)
+}
+
+// DecodeData 解码回滚数据本身，例如 eth_call 直接返回的数据
+func (d *Decoder) DecodeData(data []byte) error {
+	return d.decode(data, nil)
+}
+
+func (d *Decoder) decode(data []byte, cause error) error {
+	if len(data) < 4 {
+		return &UnknownError{Data: data, Err: cause}
+	}
+	selector, payload := data[:4], data[4:]
+	switch {
+	case bytes.Equal(selector, errorSelector):
+		if s, err := unpackOne("string", payload); err == nil {
+			return &ReasonError{Reason: s.(string), Err: cause}
+		}
+	case bytes.Equal(selector, panicSelector):
+		if code, err := unpackOne("uint256", payload); err == nil {
+			return &PanicError{Code: code.(*big.Int), Err: cause}
+		}
+	default:
+		if e, ok := d.custom[[4]byte(selector)]; ok {
+			if args, err := e.Inputs.Unpack(payload); err == nil {
+				return &CustomError{ABI: e, Args: args, Err: cause}
+			}
+		}
+	}
+	return &UnknownError{Data: data, Err: cause}
+}
+
+func unpackOne(typ string, data []byte) (any, error) {
+	t, err := abi.NewType(typ, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	values, err := abi.Arguments{{Type: t}}.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// Wrap 返回一个解码 CallContract、EstimateGas 错误的合约后端，供 abigen 绑定使用
+func (d *Decoder) Wrap(backend bind.ContractBackend) bind.ContractBackend {
+	return &decodingBackend{ContractBackend: backend, decoder: d}
+}
+
+type decodingBackend struct {
+	bind.ContractBackend
+	decoder *Decoder
+}
+
+func (b *decodingBackend) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	out, err := b.ContractBackend.CallContract(ctx, call, blockNumber)
+	return out, b.decoder.Decode(err)
+}
+
+// PendingCallContract 转发给支持 pending 状态的后端，否则与 bind 一样报告不支持
+func (b *decodingBackend) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
+	pending, ok := b.ContractBackend.(bind.PendingContractCaller)
+	if !ok {
+		return nil, bind.ErrNoPendingState
+	}
+	out, err := pending.PendingCallContract(ctx, call)
+	return out, b.decoder.Decode(err)
+}
+
+func (b *decodingBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	gas, err := b.ContractBackend.EstimateGas(ctx, msg)
+	return gas, b.decoder.Decode(err)
+}
@@ -0,0 +1,151 @@
+package revert
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// rpcError 节点返回的 JSON-RPC 错误，data 对应 error.data
+type rpcError struct {
+	code int
+	msg  string
+	data any
+}
+
+func (e *rpcError) Error() string          { return e.msg }
+func (e *rpcError) ErrorCode() int         { return e.code }
+func (e *rpcError) ErrorData() interface{} { return e.data }
+
+// testMeta 声明一个自定义错误的 ABI
+var testMeta = &bind.MetaData{
+	ABI: `[{"type":"error","name":"TooLarge","inputs":[{"name":"by","type":"uint256"},{"name":"max","type":"uint256"}]}]`,
+}
+
+// encode 按 ABI 编码回滚数据：selector 加参数
+func encode(t *testing.T, selector []byte, typ string, args ...any) string {
+	t.Helper()
+	var arguments abi.Arguments
+	for range args {
+		ty, err := abi.NewType(typ, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arguments = append(arguments, abi.Argument{Type: ty})
+	}
+	packed, err := arguments.Pack(args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hexutil.Encode(append(append([]byte{}, selector...), packed...))
+}
+
+func TestDecode(t *testing.T) {
+	d, err := NewDecoder(testMeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := testMeta.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := parsed.Errors["TooLarge"].ID
+	tooLarge := id[:4]
+
+	tests := []struct {
+		name  string
+		err   error
+		want  string // 解码后的错误消息
+		check func(t *testing.T, err error)
+	}{
+		{
+			name: "Error(string)",
+			err:  &rpcError{3, "execution reverted: by must be positive", encode(t, errorSelector, "string", "by must be positive")},
+			want: "execution reverted: by must be positive",
+			check: func(t *testing.T, err error) {
+				var reason *ReasonError
+				if !errors.As(err, &reason) || reason.Reason != "by must be positive" {
+					t.Errorf("got %#v, want *ReasonError", err)
+				}
+			},
+		},
+		{
+			name: "Panic(0x11)",
+			err:  &rpcError{3, "execution reverted", encode(t, panicSelector, "uint256", big.NewInt(PanicOverflow))},
+			want: "execution reverted: panic 0x11 (arithmetic underflow or overflow)",
+			check: func(t *testing.T, err error) {
+				var panicErr *PanicError
+				if !errors.As(err, &panicErr) || panicErr.Code.Uint64() != PanicOverflow {
+					t.Errorf("got %#v, want *PanicError 0x11", err)
+				}
+			},
+		},
+		{
+			name: "custom error",
+			// 部分节点把数据放在 {"data": "0x…"} 中，错误码也不是 3
+			err:  &rpcError{-32000, "execution reverted", map[string]any{"data": encode(t, tooLarge, "uint256", big.NewInt(5), big.NewInt(3))}},
+			want: "execution reverted: TooLarge(5, 3)",
+			check: func(t *testing.T, err error) {
+				var custom *CustomError
+				if !errors.As(err, &custom) || custom.Name() != "TooLarge" || len(custom.Args) != 2 {
+					t.Errorf("got %#v, want *CustomError TooLarge", err)
+				}
+			},
+		},
+		{
+			name: "unknown selector",
+			err:  &rpcError{3, "execution reverted", "0xdeadbeef01"},
+			want: "execution reverted: unknown error 0xdeadbeef01",
+			check: func(t *testing.T, err error) {
+				var unknown *UnknownError
+				if !errors.As(err, &unknown) || hexutil.Encode(unknown.Data) != "0xdeadbeef01" {
+					t.Errorf("got %#v, want *UnknownError with the data", err)
+				}
+			},
+		},
+		{
+			name: "revert without data",
+			err:  fmt.Errorf("estimate gas: %w", &rpcError{3, "execution reverted", nil}),
+			want: "execution reverted",
+		},
+		{
+			name: "not a revert",
+			err:  &rpcError{-32000, "insufficient funds for gas * price + value", nil},
+			want: "insufficient funds for gas * price + value",
+			check: func(t *testing.T, err error) {
+				if isDecoded(err) {
+					t.Errorf("got %#v, want the RPC error unchanged", err)
+				}
+			},
+		},
+		{
+			name: "revert in an unrelated message",
+			err:  &rpcError{-32602, "invalid argument 0: hex string without 0x prefix (reverted to default)", nil},
+			want: "invalid argument 0: hex string without 0x prefix (reverted to default)",
+			check: func(t *testing.T, err error) {
+				if isDecoded(err) {
+					t.Errorf("got %#v, want the RPC error unchanged", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := d.Decode(tt.err)
+			if got.Error() != tt.want {
+				t.Errorf("Decode = %q, want %q", got.Error(), tt.want)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+			if isDecoded(got) && !errors.Is(got, tt.err) {
+				t.Errorf("decoded error does not wrap the original %v", tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultDerivationPath 以太坊第一个账户的派生路径
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// ErrMnemonicChecksum 助记词的单词都在词表中但校验和不对，通常是抄错了某个单词或顺序
+var ErrMnemonicChecksum = errors.New("invalid mnemonic checksum")
+
+//go:embed bip39_english.txt
+var englishWords string
+
+// wordIndex BIP-39 英文词表中单词到序号的映射
+var wordIndex = sync.OnceValue(func() map[string]int {
+	words := strings.Fields(englishWords)
+	index := make(map[string]int, len(words))
+	for i, w := range words {
+		index[w] = i
+	}
+	return index
+})
+
+// FromMnemonic 校验 BIP-39 助记词（英文词表和校验和）后生成种子，再按 BIP-32 派生指定路径的私钥
+func FromMnemonic(mnemonic, passphrase, path string) (Signer, error) {
+	if path == "" {
+		path = DefaultDerivationPath
+	}
+	derivation, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("mnemonic must have 12-24 words, got %d", len(words))
+	}
+	if err := checkMnemonic(words); err != nil {
+		return nil, err
+	}
+	seed, err := pbkdf2.Key(sha512.New, strings.Join(words, " "), []byte("mnemonic"+norm.NFKD.String(passphrase)), 2048, 64)
+	if err != nil {
+		return nil, err
+	}
+	key, err := derive(seed, derivation)
+	if err != nil {
+		return nil, err
+	}
+	return FromKey(key), nil
+}
+
+// checkMnemonic 确认每个单词都在词表中，且末尾的校验位等于熵的 SHA-256 前几位。
+// 不校验时抄错一个单词会静默派生出另一个（空的）账户。
+func checkMnemonic(words []string) error {
+	index := wordIndex()
+	bits := new(big.Int)
+	for i, w := range words {
+		n, ok := index[strings.ToLower(w)]
+		if !ok {
+			return fmt.Errorf("mnemonic word %d %q is not in the BIP-39 English wordlist", i+1, w)
+		}
+		bits.Lsh(bits, 11).Or(bits, big.NewInt(int64(n)))
+	}
+	// 每 32 位熵带 1 位校验和：12 个单词为 128 位熵加 4 位校验和
+	csBits := uint(len(words) * 11 / 33)
+	checksum := new(big.Int).And(bits, big.NewInt(1<<csBits-1)).Uint64()
+	entropy := new(big.Int).Rsh(bits, csBits).FillBytes(make([]byte, len(words)*11/33*4))
+	hash := sha256.Sum256(entropy)
+	if uint64(hash[0]>>(8-csBits)) != checksum {
+		return ErrMnemonicChecksum
+	}
+	return nil
+}
+
+// derive 从种子沿路径派生 secp256k1 私钥
+func derive(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	n := crypto.S256().Params().N
+	for _, index := range path {
+		var data []byte
+		if index >= 0x80000000 {
+			data = append([]byte{0}, key...)
+		} else {
+			priv, err := crypto.ToECDSA(key)
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&priv.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		il := new(big.Int).SetBytes(sum[:32])
+		if il.Cmp(n) >= 0 {
+			return nil, errors.New("invalid derived key, try the next index")
+		}
+		child := il.Add(il, new(big.Int).SetBytes(key))
+		child.Mod(child, n)
+		if child.Sign() == 0 {
+			return nil, errors.New("invalid derived key, try the next index")
+		}
+		key, chainCode = child.FillBytes(make([]byte, 32)), sum[32:]
+	}
+	return crypto.ToECDSA(key)
+}
@@ -0,0 +1,58 @@
+package signer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testMnemonic anvil/hardhat 的默认助记词，各索引的地址是公开的
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestFromMnemonic(t *testing.T) {
+	tests := []struct {
+		path string
+		want common.Address
+	}{
+		{"", common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")},
+		{"m/44'/60'/0'/0/0", common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")},
+		{"m/44'/60'/0'/0/1", common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")},
+		{"m/44'/60'/0'/0/9", common.HexToAddress("0xa0Ee7A142d267C1f36714E4a8F75612F20a79720")},
+	}
+	for _, tt := range tests {
+		s, err := FromMnemonic(testMnemonic, "", tt.path)
+		if err != nil {
+			t.Fatalf("FromMnemonic(%q): %v", tt.path, err)
+		}
+		if s.Address() != tt.want {
+			t.Errorf("FromMnemonic(%q) = %s, want %s", tt.path, s.Address(), tt.want)
+		}
+	}
+}
+
+func TestFromMnemonicInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+		want     string
+	}{
+		{"word count", "test test test", "12-24 words"},
+		{"not in wordlist", strings.Replace(testMnemonic, "junk", "junx", 1), "not in the BIP-39 English wordlist"},
+		{"bad checksum", strings.Repeat("test ", 12), ErrMnemonicChecksum.Error()},
+		{"swapped words", "junk test test test test test test test test test test test", ErrMnemonicChecksum.Error()},
+	}
+	for _, tt := range tests {
+		_, err := FromMnemonic(tt.mnemonic, "", "")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: FromMnemonic error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+	if _, err := FromMnemonic(strings.Repeat("abandon ", 11)+"about", "", ""); err != nil {
+		t.Errorf("valid BIP-39 test vector rejected: %v", err)
+	}
+	if _, err := FromMnemonic(strings.Repeat("abandon ", 12), "", ""); !errors.Is(err, ErrMnemonicChecksum) {
+		t.Errorf("12 x abandon: %v, want ErrMnemonicChecksum", err)
+	}
+}
@@ -0,0 +1,172 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// RemoteMethod 远程签名使用的 JSON-RPC 方法组
+type RemoteMethod struct {
+	List string // 列出账户
+	Sign string // 签名交易
+}
+
+var (
+	// ClefMethod Clef 的外部 API
+	ClefMethod = RemoteMethod{List: "account_list", Sign: "account_signTransaction"}
+	// NodeMethod 节点自带的 eth 命名空间（anvil、geth --dev 等）
+	NodeMethod = RemoteMethod{List: "eth_accounts", Sign: "eth_signTransaction"}
+)
+
+// remoteSigner 把签名请求转发给远程服务，本地不接触私钥
+type remoteSigner struct {
+	client  *rpc.Client
+	method  RemoteMethod
+	address common.Address
+}
+
+// signTransactionResult 与 Clef / geth 返回的结构一致
+type signTransactionResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// DialRemote 连接远程签名服务。address 为空时使用服务返回的第一个账户。
+func DialRemote(ctx context.Context, url string, method RemoteMethod, address string) (Signer, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return NewRemote(ctx, client, method, address)
+}
+
+// NewRemote 使用已有的 RPC 连接创建远程签名器
+func NewRemote(ctx context.Context, client *rpc.Client, method RemoteMethod, address string) (Signer, error) {
+	s := &remoteSigner{client: client, method: method}
+	if address != "" {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid signer address %q", address)
+		}
+		s.address = common.HexToAddress(address)
+		return s, nil
+	}
+	var accounts []common.Address
+	if err := client.CallContext(ctx, &accounts, method.List); err != nil {
+		return nil, fmt.Errorf("%s: %w", method.List, err)
+	}
+	if len(accounts) == 0 {
+		return nil, errors.New("remote signer has no accounts")
+	}
+	s.address = accounts[0]
+	return s, nil
+}
+
+func (s *remoteSigner) Address() common.Address { return s.address }
+
+func (s *remoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if chainID == nil {
+		return nil, errors.New("no chain ID specified")
+	}
+	data := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:  common.NewMixedcaseAddress(s.address),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Value: hexutil.Big(*tx.Value()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Input: &data,
+	}
+	if to := tx.To(); to != nil {
+		addr := common.NewMixedcaseAddress(*to)
+		args.To = &addr
+	}
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("unsupported tx type %d", tx.Type())
+	}
+	args.ChainID = (*hexutil.Big)(chainID)
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+
+	var res json.RawMessage
+	if err := s.client.CallContext(ctx, &res, s.method.Sign, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.method.Sign, err)
+	}
+	raw, err := signedRaw(res)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s result: %w", s.method.Sign, err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("decode signed transaction: %w", err)
+	}
+	if err := s.checkSigned(tx, signed, chainID); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// signedRaw 取出签名后的交易编码：Clef / geth 返回 {raw, tx} 对象，anvil 等节点直接返回十六进制字符串
+func signedRaw(res json.RawMessage) (hexutil.Bytes, error) {
+	var raw hexutil.Bytes
+	if len(res) > 0 && res[0] == '"' {
+		err := json.Unmarshal(res, &raw)
+		return raw, err
+	}
+	var obj signTransactionResult
+	if err := json.Unmarshal(res, &obj); err != nil {
+		return nil, err
+	}
+	if len(obj.Raw) == 0 {
+		return nil, errors.New("missing raw transaction")
+	}
+	return obj.Raw, nil
+}
+
+// checkSigned 远程服务不可信：确认返回的交易受 EIP-155 保护、链 ID 和签名者正确，
+// 且除签名外与请求逐字段一致
+func (s *remoteSigner) checkSigned(want, got *types.Transaction, chainID *big.Int) error {
+	// 不受保护的 legacy 交易可以在任何链上重放，LatestSignerForChainID 也会按 Homestead 接受它
+	if !got.Protected() {
+		return errors.New("remote signer returned a transaction without replay protection")
+	}
+	if got.ChainId().Cmp(chainID) != 0 {
+		return fmt.Errorf("remote signer returned transaction for chain %s, want %s", got.ChainId(), chainID)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), got)
+	if err != nil {
+		return err
+	}
+	if sender != s.address {
+		return fmt.Errorf("remote signer returned transaction from %s, want %s", sender.Hex(), s.address.Hex())
+	}
+	sameTo := (want.To() == nil) == (got.To() == nil) && (want.To() == nil || *want.To() == *got.To())
+	if got.Type() != want.Type() || !sameTo || !bytes.Equal(got.Data(), want.Data()) ||
+		got.Nonce() != want.Nonce() || got.Gas() != want.Gas() || got.Value().Cmp(want.Value()) != 0 ||
+		got.GasPrice().Cmp(want.GasPrice()) != 0 || got.GasTipCap().Cmp(want.GasTipCap()) != 0 ||
+		got.GasFeeCap().Cmp(want.GasFeeCap()) != 0 {
+		return errors.New("remote signer modified the transaction")
+	}
+	return nil
+}
+
+func (s *remoteSigner) TransactOpts(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error) {
+	return transactOpts(ctx, s, chainID)
+}
@@ -0,0 +1,145 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// stubSigner 实现 eth_signTransaction 的本地签名服务，tamper 可在签名前改动交易，
+// bare 时像 anvil 一样只返回十六进制编码的交易
+type stubSigner struct {
+	key    *ecdsa.PrivateKey
+	tamper func(*types.Transaction) (types.TxData, types.Signer)
+	bare   bool
+}
+
+func (s *stubSigner) Accounts() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}
+}
+
+func (s *stubSigner) SignTransaction(args apitypes.SendTxArgs) (any, error) {
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signer := types.LatestSignerForChainID((*big.Int)(args.ChainID))
+	if s.tamper != nil {
+		var data types.TxData
+		data, signer = s.tamper(tx)
+		tx = types.NewTx(data)
+	}
+	signed, err := types.SignTx(tx, signer, s.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if s.bare {
+		return hexutil.Bytes(raw), nil
+	}
+	return &signTransactionResult{Raw: raw, Tx: signed}, nil
+}
+
+func TestRemoteSignTx(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(1337)
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	other := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	dynamic := func(tx *types.Transaction) *types.DynamicFeeTx {
+		return &types.DynamicFeeTx{
+			ChainID: tx.ChainId(), Nonce: tx.Nonce(), GasTipCap: tx.GasTipCap(), GasFeeCap: tx.GasFeeCap(),
+			Gas: tx.Gas(), To: tx.To(), Value: tx.Value(), Data: tx.Data(),
+		}
+	}
+	tests := []struct {
+		name   string
+		tamper func(*types.Transaction) (types.TxData, types.Signer)
+		want   string
+		bare   bool
+	}{
+		{"honest", nil, "", false},
+		{"bare hex", nil, "", true},
+		{"bare hex chain", func(tx *types.Transaction) (types.TxData, types.Signer) {
+			d := dynamic(tx)
+			d.ChainID = big.NewInt(1)
+			return d, types.LatestSignerForChainID(big.NewInt(1))
+		}, "for chain 1", true},
+		{"recipient", func(tx *types.Transaction) (types.TxData, types.Signer) {
+			d := dynamic(tx)
+			d.To = &other
+			return d, types.LatestSignerForChainID(chainID)
+		}, "modified", false},
+		{"calldata", func(tx *types.Transaction) (types.TxData, types.Signer) {
+			d := dynamic(tx)
+			d.Data = []byte{0xde, 0xad}
+			return d, types.LatestSignerForChainID(chainID)
+		}, "modified", false},
+		{"fee cap", func(tx *types.Transaction) (types.TxData, types.Signer) {
+			d := dynamic(tx)
+			d.GasFeeCap = new(big.Int).Mul(d.GasFeeCap, big.NewInt(100))
+			return d, types.LatestSignerForChainID(chainID)
+		}, "modified", false},
+		{"chain", func(tx *types.Transaction) (types.TxData, types.Signer) {
+			d := dynamic(tx)
+			d.ChainID = big.NewInt(1)
+			return d, types.LatestSignerForChainID(big.NewInt(1))
+		}, "for chain 1", false},
+		{"unprotected legacy", func(tx *types.Transaction) (types.TxData, types.Signer) {
+			return &types.LegacyTx{
+				Nonce: tx.Nonce(), GasPrice: tx.GasFeeCap(), Gas: tx.Gas(), To: tx.To(), Value: tx.Value(), Data: tx.Data(),
+			}, types.HomesteadSigner{}
+		}, "replay protection", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rpc.NewServer()
+			if err := server.RegisterName("eth", &stubSigner{key: key, tamper: tt.tamper, bare: tt.bare}); err != nil {
+				t.Fatal(err)
+			}
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+			client, err := rpc.DialHTTP(httpServer.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			s, err := NewRemote(context.Background(), client, NodeMethod, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Address() != crypto.PubkeyToAddress(key.PublicKey) {
+				t.Fatalf("remote address %s", s.Address())
+			}
+			tx := types.NewTx(&types.DynamicFeeTx{
+				ChainID: chainID, Nonce: 7, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9),
+				Gas: 50000, To: &to, Value: big.NewInt(1), Data: []byte{0x37, 0x13, 0x03, 0xc0},
+			})
+			signed, err := s.SignTx(context.Background(), tx, chainID)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("SignTx: %v", err)
+			case tt.want == "" && signed.Hash() != types.MustSignNewTx(key, types.LatestSignerForChainID(chainID), dynamic(tx)).Hash():
+				t.Errorf("SignTx returned a different transaction")
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("SignTx error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Package signer 为交易签名提供统一接口，私钥可以来自环境变量、
+// geth keystore 文件、BIP-39 助记词或远程签名服务（Clef / eth_signTransaction）。
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer 持有一个账户并能为其签名交易
+type Signer interface {
+	// Address 签名账户地址
+	Address() common.Address
+	// SignTx 使用 chainID 对交易签名
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// TransactOpts 生成供合约绑定使用的交易参数
+	TransactOpts(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error)
+}
+
+// Options 打开签名器时需要的敏感参数，由调用方从安全的位置读取
+type Options struct {
+	Password   string // keystore 文件密码
+	Passphrase string // BIP-39 助记词的附加口令（可为空）
+}
+
+// Open 按来源字符串打开签名器，支持的格式：
+//
+//	env:<变量名>                      环境变量中的十六进制私钥
+//	hex:<私钥>                        直接给出十六进制私钥
+//	keystore:<文件>                   geth keystore JSON 文件，密码见 Options.Password
+//	mnemonic:<变量名>[#<派生路径>]      环境变量中的助记词，默认路径 m/44'/60'/0'/0/0
+//	clef:<URL>[#<地址>]               Clef，使用 account_signTransaction
+//	remote:<URL>[#<地址>]             节点，使用 eth_signTransaction
+func Open(ctx context.Context, source string, opts Options) (Signer, error) {
+	scheme, value, ok := strings.Cut(source, ":")
+	if !ok || value == "" {
+		return nil, fmt.Errorf("invalid key source %q", source)
+	}
+	value, fragment, _ := strings.Cut(value, "#")
+	switch scheme {
+	case "env":
+		hexKey := os.Getenv(value)
+		if hexKey == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", value)
+		}
+		return FromHex(hexKey)
+	case "hex":
+		return FromHex(value)
+	case "keystore":
+		return FromKeystore(value, opts.Password)
+	case "mnemonic":
+		phrase := os.Getenv(value)
+		if phrase == "" {
+			return nil, fmt.Errorf("environment variable %s is empty", value)
+		}
+		return FromMnemonic(phrase, opts.Passphrase, fragment)
+	case "clef":
+		return DialRemote(ctx, value, ClefMethod, fragment)
+	case "remote":
+		return DialRemote(ctx, value, NodeMethod, fragment)
+	}
+	return nil, fmt.Errorf("unknown key scheme %q", scheme)
+}
+
+// keySigner 持有本地私钥
+type keySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// FromKey 使用内存中的私钥
+func FromKey(key *ecdsa.PrivateKey) Signer {
+	return &keySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// FromHex 解析十六进制私钥，允许 0x 前缀
+func FromHex(hexKey string) (Signer, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		return nil, err
+	}
+	return FromKey(key), nil
+}
+
+func (s *keySigner) Address() common.Address { return s.address }
+
+func (s *keySigner) SignTx(_ context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+func (s *keySigner) TransactOpts(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error) {
+	return transactOpts(ctx, s, chainID)
+}
+
+// transactOpts 把任意 Signer 包装成 bind.TransactOpts
+func transactOpts(ctx context.Context, s Signer, chainID *big.Int) (*bind.TransactOpts, error) {
+	if chainID == nil {
+		return nil, errors.New("no chain ID specified")
+	}
+	from := s.Address()
+	return &bind.TransactOpts{
+		From: from,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(ctx, tx, chainID)
+		},
+		Context: ctx,
+	}, nil
+}
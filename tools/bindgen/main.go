@@ -0,0 +1,141 @@
+// bindgen 从 Foundry 的构建产物生成 Counter 合约的 Go 绑定，取代手工执行的 forge/jq/abigen 步骤：
+//
+//	forge build                                   # 编译，生成 out/Counter.sol/Counter.json
+//	go generate ./counter                         # 提取 abi、bytecode 到 build/，再生成 counter/counter.go
+//	go run ./tools/bindgen -check                 # 已提交的绑定与构建产物不一致时以非零状态退出
+//
+// 构建产物不存在时（例如没有安装 Foundry 的 CI），改为从 build/Counter.abi 和 build/Counter.bin 生成，
+// -check 仍可确认 counter.go 与这两个文件一致。所有路径相对于 -C 指定的目录（默认当前目录）。
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/abigen"
+)
+
+// artifact Foundry 构建产物中用到的字段
+type artifact struct {
+	ABI      json.RawMessage `json:"abi"`
+	Bytecode struct {
+		Object string `json:"object"`
+	} `json:"bytecode"`
+}
+
+// output 一个生成的文件及其内容
+type output struct {
+	path string
+	data []byte
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("bindgen: ")
+
+	dir := flag.String("C", "", "change to this directory before resolving paths")
+	artifactPath := flag.String("artifact", "out/Counter.sol/Counter.json", "Foundry build artifact")
+	compile := flag.Bool("compile", false, "run forge build before reading the artifact")
+	abiPath := flag.String("abi", "build/Counter.abi", "extracted ABI, written from the artifact or read when it is missing")
+	binPath := flag.String("bin", "build/Counter.bin", "extracted creation bytecode, written from the artifact or read when it is missing")
+	typeName := flag.String("type", "Counter", "Go type name of the binding")
+	pkg := flag.String("pkg", "counter", "Go package of the binding")
+	out := flag.String("out", "counter/counter.go", "generated binding")
+	check := flag.Bool("check", false, "fail if the committed files differ from what would be generated, without writing")
+	flag.Parse()
+
+	if *dir != "" {
+		if err := os.Chdir(*dir); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *compile {
+		cmd := exec.Command("forge", "build")
+		cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+		if err := cmd.Run(); err != nil {
+			log.Fatalf("forge build: %v", err)
+		}
+	}
+
+	abiJSON, bin, outputs, err := load(*artifactPath, *abiPath, *binPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if strings.Contains(bin, "__$") {
+		log.Fatal("bytecode has unlinked library references")
+	}
+	code, err := abigen.Bind([]string{*typeName}, []string{abiJSON}, []string{bin}, nil, *pkg, nil, nil)
+	if err != nil {
+		log.Fatalf("generate binding: %v", err)
+	}
+	outputs = append(outputs, output{*out, []byte(code)})
+
+	if *check {
+		var stale []string
+		for _, o := range outputs {
+			current, err := os.ReadFile(o.path)
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				log.Fatal(err)
+			}
+			if !bytes.Equal(current, o.data) {
+				stale = append(stale, o.path)
+			}
+		}
+		if len(stale) > 0 {
+			log.Fatalf("stale generated files (run go generate ./counter): %s", strings.Join(stale, ", "))
+		}
+		return
+	}
+	for _, o := range outputs {
+		if err := os.WriteFile(o.path, o.data, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// load 读取 ABI 和创建字节码。构建产物存在时从中提取，并返回需要同步更新的 build/ 文件；
+// 否则直接读取 build/ 中已提取的文件。
+func load(artifactPath, abiPath, binPath string) (abiJSON, bin string, outputs []output, err error) {
+	data, err := os.ReadFile(artifactPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		abiData, err := os.ReadFile(abiPath)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("no artifact at %s (run forge build or pass -compile): %w", artifactPath, err)
+		}
+		binData, err := os.ReadFile(binPath)
+		if err != nil {
+			return "", "", nil, err
+		}
+		return string(abiData), strings.TrimSpace(string(binData)), nil, nil
+	}
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	var a artifact
+	if err := json.Unmarshal(data, &a); err != nil {
+		return "", "", nil, fmt.Errorf("parse %s: %w", artifactPath, err)
+	}
+	if len(a.ABI) == 0 || a.Bytecode.Object == "" {
+		return "", "", nil, fmt.Errorf("%s has no abi or bytecode", artifactPath)
+	}
+	// 与 jq '.abi'、jq -r '.bytecode.object' 的输出格式一致
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, a.ABI, "", "  "); err != nil {
+		return "", "", nil, err
+	}
+	indented.WriteByte('\n')
+	outputs = []output{
+		{abiPath, indented.Bytes()},
+		{binPath, []byte(a.Bytecode.Object + "\n")},
+	}
+	return indented.String(), a.Bytecode.Object, outputs, nil
+}
@@ -0,0 +1,283 @@
+// Package transport 为 JSON-RPC 的 HTTP 连接加上重试、限流和单次调用超时。
+//
+// 重试只针对节点暂时不可用的情况：HTTP 429、5xx、超时和连接错误，以及以 200 返回的
+// 限流错误（JSON-RPC -32005）。回滚、参数错误、eth_getLogs 结果过多等确定性的错误原样返回。
+// 发送交易的请求只在确定节点没有处理时（429、限流、连接被拒绝）重试，
+// 避免同一笔交易重复提交后得到 already known 之类的错误。
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Options 重试和限流参数
+type Options struct {
+	MaxAttempts int           // 每次调用最多尝试的次数（含第一次），默认 4
+	BaseDelay   time.Duration // 第一次重试前的等待，之后每次翻倍并加随机抖动，默认 250ms
+	MaxDelay    time.Duration // 单次等待的上限，默认 8s
+	CallTimeout time.Duration // 单次调用（含重试）的超时，与调用方 ctx 的截止时间取较早者；0 表示不限
+	Rate        float64       // 每秒请求数上限，0 表示不限流
+	Burst       int           // 令牌桶容量，默认 1
+	// OnRetry 可选，重试前回调，attempt 为即将进行的第几次尝试
+	OnRetry func(attempt int, delay time.Duration, err error)
+	// Wrap 可选，包在重试和限流外层，只看到每次调用的最终结果，例如录制 cassette
+	Wrap func(http.RoundTripper) http.RoundTripper
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxAttempts < 1 {
+		o.MaxAttempts = 4
+	}
+	if o.BaseDelay <= 0 {
+		o.BaseDelay = 250 * time.Millisecond
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = 8 * time.Second
+	}
+	return o
+}
+
+// Transport 带重试和限流的 http.RoundTripper，每个实例有自己的令牌桶，一个节点一个实例
+type Transport struct {
+	base    http.RoundTripper
+	opts    Options
+	limiter *Limiter
+}
+
+// New 包装 base（nil 时使用 http.DefaultTransport）
+func New(base http.RoundTripper, opts Options) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	opts = opts.withDefaults()
+	return &Transport{base: base, opts: opts, limiter: NewLimiter(opts.Rate, opts.Burst)}
+}
+
+// Dial 连接 JSON-RPC 节点。HTTP(S) 节点经过 Transport；WebSocket 和 IPC 连接不经过 HTTP，
+// 不做重试和限流。
+func Dial(ctx context.Context, url string, opts Options) (*rpc.Client, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return rpc.DialContext(ctx, url)
+	}
+	var rt http.RoundTripper = New(nil, opts)
+	if opts.Wrap != nil {
+		rt = opts.Wrap(rt)
+	}
+	client := &http.Client{Transport: rt}
+	return rpc.DialOptions(ctx, url, rpc.WithHTTPClient(client))
+}
+
+// StatusError 触发重试的 HTTP 状态或限流错误，作为重试原因传给 OnRetry
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+	}
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// RoundTrip 发送请求，遇到可重试的错误时按指数退避重试，直到成功、不可重试或超时
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if t.opts.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.opts.CallTimeout)
+		defer cancel()
+	}
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	sends := sendsTransaction(body)
+
+	for attempt := 1; ; attempt++ {
+		if err := t.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, data, err := t.do(ctx, req, body)
+		retry, after, cause := classify(resp, data, err, sends)
+		if ctx.Err() != nil || !retry || attempt >= t.opts.MaxAttempts {
+			return resp, err
+		}
+		delay := max(min(after, t.opts.MaxDelay), t.backoff(attempt))
+		if t.opts.OnRetry != nil {
+			t.opts.OnRetry(attempt+1, delay, cause)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		}
+	}
+}
+
+// do 发送一次请求并读完响应体：判断是否为限流错误需要读取内容，
+// 之后 ctx 取消也不影响调用方读取
+func (t *Transport) do(ctx context.Context, req *http.Request, body []byte) (*http.Response, []byte, error) {
+	r := req.Clone(ctx)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return resp, data, nil
+}
+
+// backoff 第 attempt 次失败后的等待：BaseDelay·2^(attempt-1)，上限 MaxDelay，
+// 取其一半加上随机的另一半，避免多个客户端同时重试
+func (t *Transport) backoff(attempt int) time.Duration {
+	d := t.opts.MaxDelay
+	if shift := attempt - 1; shift < 30 {
+		d = min(d, t.opts.BaseDelay<<shift)
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// sendsTransaction 请求（或批量请求中的任意一个）是否提交交易
+func sendsTransaction(body []byte) bool {
+	var reqs []struct {
+		Method string `json:"method"`
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] != '[' {
+		body = append(append([]byte{'['}, body...), ']')
+	}
+	if err := json.Unmarshal(body, &reqs); err != nil {
+		return true // 无法解析时按最保守的情况处理
+	}
+	for _, r := range reqs {
+		if r.Method == "eth_sendRawTransaction" || r.Method == "eth_sendTransaction" {
+			return true
+		}
+	}
+	return false
+}
+
+// classify 判断一次尝试的结果是否应该重试，after 为节点要求的最短等待（Retry-After），
+// cause 描述失败原因。sends 为 true 时只在确定请求未被处理时重试。
+func classify(resp *http.Response, data []byte, err error, sends bool) (retry bool, after time.Duration, cause error) {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false, 0, err
+		}
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return true, 0, err
+		}
+		// 域名不存在是配置错误，重试也不会成功
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, 0, err
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) {
+			return !sends, 0, err
+		}
+		return false, 0, err
+	}
+	switch code := resp.StatusCode; {
+	case code == http.StatusTooManyRequests:
+		return true, retryAfter(resp), &StatusError{StatusCode: code}
+	case code == http.StatusInternalServerError, code == http.StatusBadGateway,
+		code == http.StatusServiceUnavailable, code == http.StatusGatewayTimeout:
+		return !sends, retryAfter(resp), &StatusError{StatusCode: code}
+	case code == http.StatusOK:
+		if msg, ok := rateLimited(data); ok {
+			return true, 0, &StatusError{StatusCode: code, Message: msg}
+		}
+	}
+	return false, 0, nil
+}
+
+// retryAfter 解析 Retry-After 头（秒数或 HTTP 日期），等待时间不超过 MaxDelay
+func retryAfter(resp *http.Response) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(0, time.Until(at))
+	}
+	return 0
+}
+
+// resultLimits 结果数量或区块范围超限的报错片段。Infura 等节点对这类 eth_getLogs 错误
+// 也使用 -32005（例如 query returned more than 10000 results），重试不会成功，应缩小查询范围
+var resultLimits = []string{"query returned more than", "block range", "response size"}
+
+// ResultLimit 判断 JSON-RPC 错误消息是否说明结果数量或区块范围超限，而不是限流
+func ResultLimit(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, s := range resultLimits {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimited 识别以 200 返回的限流错误：JSON-RPC 错误码 -32005，或消息中包含 rate limit 等字样。
+// 结果数量或区块范围超限不算限流。
+func rateLimited(data []byte) (string, bool) {
+	var resps []struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '[' {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+	if err := json.Unmarshal(data, &resps); err != nil {
+		return "", false
+	}
+	for _, r := range resps {
+		if r.Error == nil || ResultLimit(r.Error.Message) {
+			continue
+		}
+		msg := strings.ToLower(r.Error.Message)
+		if r.Error.Code == -32005 || strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests") {
+			return r.Error.Message, true
+		}
+	}
+	return "", false
+}
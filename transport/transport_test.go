@@ -0,0 +1,243 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// stub 按顺序返回预设的响应，用完后返回正常结果
+type stub struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	replies []func(w http.ResponseWriter, id json.RawMessage)
+	calls   int
+}
+
+func newStub(t *testing.T, replies ...func(w http.ResponseWriter, id json.RawMessage)) *stub {
+	t.Helper()
+	s := &stub{replies: replies}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stub) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.calls++
+	var reply func(w http.ResponseWriter, id json.RawMessage)
+	if len(s.replies) > 0 {
+		reply, s.replies = s.replies[0], s.replies[1:]
+	}
+	s.mu.Unlock()
+	if reply == nil {
+		reply = result("0x1")
+	}
+	reply(w, req.ID)
+}
+
+func (s *stub) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func status(code int) func(http.ResponseWriter, json.RawMessage) {
+	return func(w http.ResponseWriter, _ json.RawMessage) {
+		http.Error(w, http.StatusText(code), code)
+	}
+}
+
+func result(v any) func(http.ResponseWriter, json.RawMessage) {
+	return func(w http.ResponseWriter, id json.RawMessage) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": id, "result": v})
+	}
+}
+
+func rpcError(code int, msg string) func(http.ResponseWriter, json.RawMessage) {
+	return func(w http.ResponseWriter, id json.RawMessage) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": id, "error": map[string]any{"code": code, "message": msg}})
+	}
+}
+
+func dial(t *testing.T, url string, opts Options) (*rpc.Client, *[]error) {
+	t.Helper()
+	var retries []error
+	opts.BaseDelay = time.Millisecond
+	opts.OnRetry = func(attempt int, delay time.Duration, err error) { retries = append(retries, err) }
+	c, err := Dial(context.Background(), url, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c, &retries
+}
+
+func TestRetryTransient(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		replies []func(http.ResponseWriter, json.RawMessage)
+		calls   int
+		wantErr bool
+	}{
+		{"429 retried", "eth_blockNumber", []func(http.ResponseWriter, json.RawMessage){status(429), status(429)}, 3, false},
+		{"503 retried", "eth_blockNumber", []func(http.ResponseWriter, json.RawMessage){status(503)}, 2, false},
+		{"rate limit error retried", "eth_blockNumber", []func(http.ResponseWriter, json.RawMessage){rpcError(-32005, "limit exceeded")}, 2, false},
+		{"rate limit message retried", "eth_blockNumber", []func(http.ResponseWriter, json.RawMessage){rpcError(-32000, "Too Many Requests")}, 2, false},
+		{"gives up after MaxAttempts", "eth_blockNumber", []func(http.ResponseWriter, json.RawMessage){status(429), status(429), status(429), status(429)}, 3, true},
+		{"400 not retried", "eth_blockNumber", []func(http.ResponseWriter, json.RawMessage){status(400)}, 1, true},
+		{"too many results not retried", "eth_getLogs", []func(http.ResponseWriter, json.RawMessage){rpcError(-32005, "query returned more than 10000 results")}, 1, true},
+		{"node error not retried", "eth_call", []func(http.ResponseWriter, json.RawMessage){rpcError(3, "execution reverted")}, 1, true},
+		{"send not retried on 500", "eth_sendRawTransaction", []func(http.ResponseWriter, json.RawMessage){status(500)}, 1, true},
+		{"send retried on 429", "eth_sendRawTransaction", []func(http.ResponseWriter, json.RawMessage){status(429)}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStub(t, tt.replies...)
+			c, retries := dial(t, s.URL, Options{MaxAttempts: 3})
+			var res string
+			err := c.CallContext(context.Background(), &res, tt.method)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("call: %v, want error %v", err, tt.wantErr)
+			}
+			if s.count() != tt.calls {
+				t.Errorf("server saw %d calls, want %d", s.count(), tt.calls)
+			}
+			if len(*retries) != tt.calls-1 {
+				t.Errorf("OnRetry called %d times, want %d", len(*retries), tt.calls-1)
+			}
+		})
+	}
+}
+
+func TestResultLimitPassesThrough(t *testing.T) {
+	const msg = "query returned more than 10000 results. Try with this block range [0x1, 0x2710]."
+	s := newStub(t, rpcError(-32005, msg))
+	c, retries := dial(t, s.URL, Options{})
+	var res []any
+	err := c.CallContext(context.Background(), &res, "eth_getLogs", map[string]any{"fromBlock": "0x0", "toBlock": "latest"})
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32005 || rpcErr.Error() != msg {
+		t.Fatalf("eth_getLogs: %v, want the node's -32005 error unchanged", err)
+	}
+	if s.count() != 1 || len(*retries) != 0 {
+		t.Errorf("server saw %d calls with %d retries, want 1 and 0", s.count(), len(*retries))
+	}
+}
+
+func TestClassifyResultLimit(t *testing.T) {
+	tests := []struct {
+		code  int
+		msg   string
+		retry bool
+	}{
+		{-32005, "query returned more than 10000 results", false},
+		{-32005, "Log response size exceeded", false},
+		{-32000, "block range is too wide", false},
+		// 限流消息里出现 "more than" 不代表结果超限
+		{-32005, "rate limit exceeded: more than 10 requests per second", true},
+		{-32000, "Too many requests, more than 100 per minute", true},
+		{-32005, "limit exceeded", true},
+	}
+	for _, tt := range tests {
+		body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "error": map[string]any{"code": tt.code, "message": tt.msg}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		retry, _, _ := classify(&http.Response{StatusCode: http.StatusOK}, body, nil, false)
+		if retry != tt.retry {
+			t.Errorf("classify(%d %q) retry = %v, want %v", tt.code, tt.msg, retry, tt.retry)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	s := newStub(t, func(w http.ResponseWriter, _ json.RawMessage) {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	})
+	var delays []time.Duration
+	c, err := Dial(context.Background(), s.URL, Options{
+		BaseDelay: time.Millisecond,
+		MaxDelay:  2 * time.Second,
+		OnRetry:   func(_ int, delay time.Duration, _ error) { delays = append(delays, delay) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var res string
+	if err := c.CallContext(context.Background(), &res, "eth_blockNumber"); err != nil {
+		t.Fatal(err)
+	}
+	if len(delays) != 1 || delays[0] < time.Second {
+		t.Errorf("retry delays %v, want one delay of at least Retry-After", delays)
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	replies := make([]func(http.ResponseWriter, json.RawMessage), 100)
+	for i := range replies {
+		replies[i] = status(503)
+	}
+	s := newStub(t, replies...)
+	c, _ := dial(t, s.URL, Options{MaxAttempts: 100, CallTimeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	var res string
+	err := c.CallContext(context.Background(), &res, "eth_blockNumber")
+	if err == nil {
+		t.Fatal("call succeeded, want the deadline to stop retries")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("call took %s, want it bounded by CallTimeout", elapsed)
+	}
+	if s.count() >= 100 {
+		t.Errorf("server saw %d calls, retries were not stopped", s.count())
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(100, 2)
+	ctx := context.Background()
+	start := time.Now()
+	for range 6 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 个令牌立即可用，其余 4 个按每秒 100 个补充
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("6 requests took %s, want at least 40ms", elapsed)
+	}
+
+	// 等待被取消时返回 ctx 的错误
+	cctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	slow := NewLimiter(0.1, 1)
+	slow.Wait(ctx)
+	if err := slow.Wait(cctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait: %v, want context.DeadlineExceeded", err)
+	}
+	if NewLimiter(0, 1).Wait(ctx) != nil {
+		t.Error("zero rate should not limit")
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"sepolia-block/chain"
+	"sepolia-block/counter"
+	"sepolia-block/i18n"
+	"sepolia-block/output"
+	"sepolia-block/revert"
+	"sepolia-block/txmgr"
+)
+
+// txFlags 发送交易的命令共享的参数
+type txFlags struct {
+	confirmations  uint64
+	noWait         bool
+	simulate       boolFlag
+	timeout        time.Duration
+	feeStrategy    string
+	maxFee         string
+	maxPriorityFee string
+	gasLimit       uint64
+	gasMultiplier  float64
+	gasCap         uint64
+	nonceFile      string
+}
+
+func (t *txFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&t.feeStrategy, "fee-strategy", "", msgs.Sprintf(i18n.FlagFeeStrategy))
+	fs.StringVar(&t.maxFee, "max-fee", "", msgs.Sprintf(i18n.FlagMaxFee))
+	fs.StringVar(&t.maxPriorityFee, "max-priority-fee", "", msgs.Sprintf(i18n.FlagMaxPriorityFee))
+	fs.Uint64Var(&t.gasLimit, "gas-limit", 0, msgs.Sprintf(i18n.FlagGasLimit))
+	fs.Float64Var(&t.gasMultiplier, "gas-multiplier", 1.2, msgs.Sprintf(i18n.FlagGasMultiplier))
+	fs.Uint64Var(&t.gasCap, "gas-cap", 0, msgs.Sprintf(i18n.FlagGasCap))
+	fs.StringVar(&t.nonceFile, "nonce-file", defaultNonceFile(), msgs.Sprintf(i18n.FlagNonceFile))
+	fs.Uint64Var(&t.confirmations, "confirmations", 1, msgs.Sprintf(i18n.FlagConfirmations))
+	fs.BoolVar(&t.noWait, "no-wait", false, msgs.Sprintf(i18n.FlagNoWait))
+	fs.Var(&t.simulate, "simulate", msgs.Sprintf(i18n.FlagSimulate))
+	fs.DurationVar(&t.timeout, "timeout", 5*time.Minute, msgs.Sprintf(i18n.FlagTimeout))
+}
+
+// fees 按 -fee-strategy 估算手续费，未指定时使用配置中的策略
+func (g *globalFlags) fees(ctx context.Context, backend txmgr.FeeBackend) (txmgr.Fees, error) {
+	name := g.tx.feeStrategy
+	if name == "" {
+		name = g.cfg.FeeStrategy
+	}
+	strategy, err := txmgr.ParseFeeStrategy(name)
+	if err != nil {
+		return txmgr.Fees{}, err
+	}
+	oracle := txmgr.NewFeeOracle(backend)
+	oracle.Strategy = strategy
+	if oracle.FeeCap, err = parseWei("max-fee", g.tx.maxFee); err != nil {
+		return txmgr.Fees{}, err
+	}
+	if oracle.TipCap, err = parseWei("max-priority-fee", g.tx.maxPriorityFee); err != nil {
+		return txmgr.Fees{}, err
+	}
+	fees, err := oracle.Suggest(ctx)
+	if err != nil {
+		return txmgr.Fees{}, err
+	}
+	fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressFees, strategy, fees))
+	return fees, nil
+}
+
+// gasEstimator 按 -gas-multiplier、-gas-cap 创建估算器，并在发送前打印估算结果
+func (g *globalFlags) gasEstimator(backend ethereum.GasEstimator) *txmgr.GasEstimator {
+	est := txmgr.NewGasEstimator(backend)
+	est.Multiplier = g.tx.gasMultiplier
+	est.Cap = g.tx.gasCap
+	est.OnEstimate = func(_ ethereum.CallMsg, estimated, limit uint64) {
+		fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressGas, estimated, limit))
+	}
+	return est
+}
+
+// backend 返回供合约绑定使用的后端：gas 估算走 gasEstimator，
+// 调用和估算的回滚数据按 Counter ABI 解码为 revert 包中的错误类型，
+// 开启 -simulate 时发送前先在 pending 区块上模拟
+func (g *globalFlags) backend(client *chain.Client) (bind.ContractBackend, error) {
+	decoder, err := revert.NewDecoder(counter.CounterMetaData)
+	if err != nil {
+		return nil, err
+	}
+	sim := txmgr.NewSimulator(client, decoder)
+	sim.Enabled = g.simulate()
+	return sim.Wrap(decoder.Wrap(g.gasEstimator(client).Wrap(client))), nil
+}
+
+// simulate 是否在发送前模拟：-simulate 优先，否则按配置项 simulate
+func (g *globalFlags) simulate() bool {
+	if v := g.tx.simulate.value; v != nil {
+		return *v
+	}
+	return g.cfg.Simulate != nil && *g.cfg.Simulate
+}
+
+// boolFlag 布尔参数，未在命令行指定时 value 为 nil，以便回落到配置
+type boolFlag struct {
+	value *bool
+}
+
+func (b *boolFlag) String() string {
+	if b == nil || b.value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b.value)
+}
+
+func (b *boolFlag) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	b.value = &v
+	return nil
+}
+
+func (b *boolFlag) IsBoolFlag() bool { return true }
+
+// defaultNonceFile 用户缓存目录下的 nonce 文件
+func defaultNonceFile() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sepolia-block", "nonces.json")
+}
+
+func (g *globalFlags) nonceManager(backend txmgr.NonceBackend) (*txmgr.NonceManager, error) {
+	return txmgr.NewNonceManager(backend, g.tx.nonceFile)
+}
+
+// parseWei 解析十进制 wei 数值，空字符串返回 nil
+func parseWei(name, s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, msgs.Errorf(i18n.ErrInvalidFlag, name, s)
+	}
+	return v, nil
+}
+
+// receiptInfo 交易确认后的摘要
+type receiptInfo struct {
+	TxHash            string   `json:"tx_hash"`
+	Status            string   `json:"status"`
+	Block             uint64   `json:"block"`
+	Confirmations     uint64   `json:"confirmations"`
+	GasUsed           uint64   `json:"gas_used"`
+	EffectiveGasPrice *big.Int `json:"effective_gas_price"`
+	Fee               *big.Int `json:"fee"`
+	Reorgs            int      `json:"reorgs,omitempty"`
+}
+
+// receiptColumns 交易类结果在 CSV 中共用的回执列
+var receiptColumns = []string{"status", "block", "confirmations", "gas_used", "effective_gas_price", "fee"}
+
+func (r *receiptInfo) values() []string {
+	if r == nil {
+		return make([]string, len(receiptColumns))
+	}
+	return []string{
+		r.Status,
+		strconv.FormatUint(r.Block, 10),
+		strconv.FormatUint(r.Confirmations, 10),
+		strconv.FormatUint(r.GasUsed, 10),
+		r.EffectiveGasPrice.String(),
+		r.Fee.String(),
+	}
+}
+
+func (r *receiptInfo) lines() []string {
+	if r == nil {
+		return nil
+	}
+	return []string{
+		msgs.Sprintf(i18n.ReceiptStatus, r.Status, r.Block, r.Confirmations),
+		msgs.Sprintf(i18n.ReceiptGas, r.GasUsed, r.EffectiveGasPrice, r.Fee),
+	}
+}
+
+// tracker 按 -confirmations 创建 Tracker，等待进度输出到标准错误
+func (g *globalFlags) tracker(backend txmgr.ReceiptBackend) *txmgr.Tracker {
+	tracker := txmgr.NewTracker(backend)
+	tracker.Confirmations = g.tx.confirmations
+	tracker.OnProgress = func(p txmgr.Progress) {
+		switch {
+		case p.Reorged:
+			fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressReorged, p.TxHash.Hex()))
+		case p.Confirmations > 0:
+			fmt.Fprintln(os.Stderr, msgs.Sprintf(i18n.ProgressConfirmations, p.Confirmations, g.tx.confirmations))
+		}
+	}
+	return tracker
+}
+
+// wait 按 txFlags 等待交易确认，传入多个哈希时等待其中任意一笔（同 nonce 的替换交易）。
+// 交易执行失败时同时返回回执摘要和 *txmgr.RevertedError。
+func (g *globalFlags) wait(backend txmgr.ReceiptBackend, hashes ...common.Hash) (*receiptInfo, error) {
+	if g.tx.noWait {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), g.tx.timeout)
+	defer cancel()
+
+	res, err := g.tracker(backend).WaitAny(ctx, hashes...)
+	if res == nil {
+		return nil, err
+	}
+	info := &receiptInfo{
+		TxHash:            res.Receipt.TxHash.Hex(),
+		Status:            "success",
+		Block:             res.Receipt.BlockNumber.Uint64(),
+		Confirmations:     res.Confirmations,
+		GasUsed:           res.GasUsed,
+		EffectiveGasPrice: res.EffectiveGasPrice,
+		Fee:               res.Fee,
+		Reorgs:            res.Reorgs,
+	}
+	if err != nil {
+		info.Status = "reverted"
+	}
+	return info, err
+}
+
+// printTx 打印交易结果；等待回执时出错也先输出已有信息再返回错误
+func (g *globalFlags) printTx(rec output.Record, waitErr error) error {
+	if err := g.print(rec); err != nil {
+		return err
+	}
+	return waitErr
+}
@@ -0,0 +1,226 @@
+package txmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// FeeStrategy 手续费策略
+type FeeStrategy string
+
+const (
+	Slow   FeeStrategy = "slow"
+	Normal FeeStrategy = "normal"
+	Fast   FeeStrategy = "fast"
+	Custom FeeStrategy = "custom" // 使用 FeeOracle.TipCap / FeeCap
+)
+
+// strategyParams 各策略使用的小费分位数和 baseFee 放大倍数（num/den）
+var strategyParams = map[FeeStrategy]struct {
+	percentile float64
+	num, den   int64
+}{
+	Slow:   {10, 5, 4},
+	Normal: {50, 2, 1},
+	Fast:   {90, 3, 1},
+}
+
+// ParseFeeStrategy 解析策略名
+func ParseFeeStrategy(s string) (FeeStrategy, error) {
+	switch st := FeeStrategy(s); st {
+	case Slow, Normal, Fast, Custom:
+		return st, nil
+	}
+	return "", fmt.Errorf("unknown fee strategy %q", s)
+}
+
+// FeeBackend 估算手续费所需的节点接口
+type FeeBackend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+}
+
+// Fees 交易的手续费参数。GasPrice 非空表示链未启用 London，只能发 legacy 交易。
+type Fees struct {
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// Dynamic 是否为 EIP-1559 手续费
+func (f Fees) Dynamic() bool { return f.GasPrice == nil }
+
+// Apply 把手续费写入 TransactOpts，合约绑定将据此构造交易
+func (f Fees) Apply(opts *bind.TransactOpts) {
+	opts.GasPrice, opts.GasTipCap, opts.GasFeeCap = f.GasPrice, f.GasTipCap, f.GasFeeCap
+}
+
+func (f Fees) String() string {
+	if !f.Dynamic() {
+		return fmt.Sprintf("gasPrice %s wei (legacy)", f.GasPrice)
+	}
+	return fmt.Sprintf("maxFeePerGas %s wei, maxPriorityFeePerGas %s wei", f.GasFeeCap, f.GasTipCap)
+}
+
+// NewTx 按手续费类型构造 DynamicFeeTx 或 legacy 交易
+func (f Fees) NewTx(chainID *big.Int, nonce uint64, to *common.Address, value *big.Int, gas uint64, data []byte) *types.Transaction {
+	if !f.Dynamic() {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: f.GasPrice,
+			Gas:      gas,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: f.GasTipCap,
+		GasFeeCap: f.GasFeeCap,
+		Gas:       gas,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	})
+}
+
+// FeeOracle 根据 eth_feeHistory 和 eth_maxPriorityFeePerGas 给出手续费
+type FeeOracle struct {
+	backend FeeBackend
+
+	Strategy FeeStrategy
+	Blocks   uint64   // feeHistory 统计的区块数
+	TipCap   *big.Int // Custom 策略的小费；其他策略下为小费上限
+	FeeCap   *big.Int // Custom 策略的最高单价，legacy 链上作为 gasPrice；其他策略下为上限
+}
+
+// NewFeeOracle 创建默认使用 Normal 策略、统计最近 20 个区块的 FeeOracle
+func NewFeeOracle(backend FeeBackend) *FeeOracle {
+	return &FeeOracle{backend: backend, Strategy: Normal, Blocks: 20}
+}
+
+// Suggest 给出下一笔交易的手续费，London 之前的链退回 legacy gasPrice
+func (o *FeeOracle) Suggest(ctx context.Context) (Fees, error) {
+	head, err := o.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return Fees{}, err
+	}
+	if head.BaseFee == nil {
+		return o.legacy(ctx)
+	}
+	if o.Strategy == Custom {
+		return o.custom(head.BaseFee)
+	}
+	params, ok := strategyParams[o.Strategy]
+	if !ok {
+		return Fees{}, fmt.Errorf("unknown fee strategy %q", o.Strategy)
+	}
+
+	history, err := o.backend.FeeHistory(ctx, o.Blocks, nil, []float64{params.percentile})
+	if err != nil {
+		return Fees{}, err
+	}
+	tip := medianReward(history)
+	// 历史区块为空时，或普通/快速策略下不低于节点建议的小费
+	if tip == nil || o.Strategy != Slow {
+		suggested, err := o.backend.SuggestGasTipCap(ctx)
+		if err != nil {
+			return Fees{}, err
+		}
+		if tip == nil || suggested.Cmp(tip) > 0 {
+			tip = suggested
+		}
+	}
+	// BaseFee 的最后一项是下一个区块的 baseFee
+	baseFee := head.BaseFee
+	if n := len(history.BaseFee); n > 0 && history.BaseFee[n-1] != nil {
+		baseFee = history.BaseFee[n-1]
+	}
+	feeCap := new(big.Int).Mul(baseFee, big.NewInt(params.num))
+	feeCap.Div(feeCap, big.NewInt(params.den))
+	feeCap.Add(feeCap, tip)
+	return o.bound(Fees{GasTipCap: tip, GasFeeCap: feeCap}), nil
+}
+
+// bound 非 Custom 策略下把估算结果限制在 TipCap、FeeCap 以内，小费不超过最高单价
+func (o *FeeOracle) bound(f Fees) Fees {
+	limit := func(v, upper *big.Int) *big.Int {
+		if upper != nil && v.Cmp(upper) > 0 {
+			return new(big.Int).Set(upper)
+		}
+		return v
+	}
+	if !f.Dynamic() {
+		f.GasPrice = limit(f.GasPrice, o.FeeCap)
+		return f
+	}
+	f.GasFeeCap = limit(f.GasFeeCap, o.FeeCap)
+	f.GasTipCap = limit(limit(f.GasTipCap, o.TipCap), f.GasFeeCap)
+	return f
+}
+
+func (o *FeeOracle) legacy(ctx context.Context) (Fees, error) {
+	if o.Strategy == Custom {
+		if o.FeeCap == nil {
+			return Fees{}, errors.New("custom fee strategy on a legacy chain requires a fee cap")
+		}
+		return Fees{GasPrice: o.FeeCap}, nil
+	}
+	price, err := o.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return Fees{}, err
+	}
+	// legacy 链没有 baseFee，按策略在建议价格上下浮动
+	switch o.Strategy {
+	case Slow:
+		price = new(big.Int).Div(new(big.Int).Mul(price, big.NewInt(9)), big.NewInt(10))
+	case Fast:
+		price = new(big.Int).Div(new(big.Int).Mul(price, big.NewInt(5)), big.NewInt(4))
+	}
+	return o.bound(Fees{GasPrice: price}), nil
+}
+
+func (o *FeeOracle) custom(baseFee *big.Int) (Fees, error) {
+	if o.TipCap == nil {
+		return Fees{}, errors.New("custom fee strategy requires a priority fee")
+	}
+	feeCap := o.FeeCap
+	if feeCap == nil {
+		feeCap = new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), o.TipCap)
+	}
+	if feeCap.Cmp(o.TipCap) < 0 {
+		return Fees{}, fmt.Errorf("maxFeePerGas (%v) < maxPriorityFeePerGas (%v)", feeCap, o.TipCap)
+	}
+	return Fees{GasTipCap: o.TipCap, GasFeeCap: feeCap}, nil
+}
+
+// medianReward 取各区块指定分位小费的中位数，忽略空区块
+func medianReward(history *ethereum.FeeHistory) *big.Int {
+	var rewards []*big.Int
+	for i, r := range history.Reward {
+		if len(r) == 0 || r[0] == nil {
+			continue
+		}
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		rewards = append(rewards, r[0])
+	}
+	if len(rewards) == 0 {
+		return nil
+	}
+	sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+	return new(big.Int).Set(rewards[len(rewards)/2])
+}
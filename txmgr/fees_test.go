@@ -0,0 +1,70 @@
+package txmgr
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// feeNode 固定的手续费数据：baseFee 为 nil 时模拟未启用 London 的链
+type feeNode struct {
+	baseFee, tip, price *big.Int
+}
+
+func (n feeNode) HeaderByNumber(context.Context, *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: n.baseFee}, nil
+}
+
+func (n feeNode) SuggestGasPrice(context.Context) (*big.Int, error) { return n.price, nil }
+
+func (n feeNode) SuggestGasTipCap(context.Context) (*big.Int, error) { return n.tip, nil }
+
+func (n feeNode) FeeHistory(context.Context, uint64, *big.Int, []float64) (*ethereum.FeeHistory, error) {
+	return &ethereum.FeeHistory{BaseFee: []*big.Int{n.baseFee}}, nil
+}
+
+func TestFeeCapsBoundStrategies(t *testing.T) {
+	ctx := context.Background()
+	london := feeNode{baseFee: big.NewInt(100), tip: big.NewInt(10)}
+
+	// fast：feeCap = 3 × baseFee + tip = 310，不设上限时原样返回
+	o := NewFeeOracle(london)
+	o.Strategy = Fast
+	fees, err := o.Suggest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fees.GasFeeCap.Int64() != 310 || fees.GasTipCap.Int64() != 10 {
+		t.Fatalf("fast fees %v, want 310/10", fees)
+	}
+
+	o.FeeCap, o.TipCap = big.NewInt(250), big.NewInt(4)
+	if fees, err = o.Suggest(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fees.GasFeeCap.Int64() != 250 || fees.GasTipCap.Int64() != 4 {
+		t.Errorf("bounded fast fees %v, want 250/4", fees)
+	}
+
+	// 小费不超过最高单价
+	o.FeeCap, o.TipCap = big.NewInt(5), nil
+	if fees, err = o.Suggest(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fees.GasFeeCap.Int64() != 5 || fees.GasTipCap.Int64() != 5 {
+		t.Errorf("fees with cap below tip %v, want 5/5", fees)
+	}
+
+	// legacy 链上 FeeCap 限制 gasPrice
+	o = NewFeeOracle(feeNode{price: big.NewInt(1000)})
+	o.Strategy, o.FeeCap = Fast, big.NewInt(900)
+	if fees, err = o.Suggest(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if fees.GasPrice.Int64() != 900 {
+		t.Errorf("bounded legacy gasPrice %v, want 900", fees.GasPrice)
+	}
+}
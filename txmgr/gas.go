@@ -0,0 +1,87 @@
+package txmgr
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// GasCapError 估算值超过了允许的上限
+type GasCapError struct {
+	Estimated uint64
+	Cap       uint64
+}
+
+func (e *GasCapError) Error() string {
+	return fmt.Sprintf("estimated gas %d exceeds cap %d", e.Estimated, e.Cap)
+}
+
+// GasEstimator 调用 eth_estimateGas 并加上安全余量
+type GasEstimator struct {
+	backend ethereum.GasEstimator
+
+	Multiplier float64 // 安全系数，作用于估算值
+	Cap        uint64  // gas 上限，0 表示不限制
+	// OnEstimate 可选，在返回 gas limit 前回调，便于发送前展示
+	OnEstimate func(msg ethereum.CallMsg, estimated, limit uint64)
+}
+
+// NewGasEstimator 创建默认 1.2 倍余量、不设上限的 GasEstimator
+func NewGasEstimator(backend ethereum.GasEstimator) *GasEstimator {
+	return &GasEstimator{backend: backend, Multiplier: 1.2}
+}
+
+// Estimate 按实际调用消息估算 gas limit
+func (e *GasEstimator) Estimate(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	estimated, err := e.backend.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, err
+	}
+	if e.Cap != 0 && estimated > e.Cap {
+		return 0, &GasCapError{Estimated: estimated, Cap: e.Cap}
+	}
+	limit := estimated
+	// 不带数据、只花固有 gas 的转账结果是确定的，不需要余量
+	if !(len(msg.Data) == 0 && estimated == params.TxGas) && e.Multiplier > 1 {
+		scaled := math.Ceil(float64(estimated) * e.Multiplier)
+		if scaled >= math.MaxUint64 {
+			limit = math.MaxUint64
+		} else {
+			limit = uint64(scaled)
+		}
+	}
+	if e.Cap != 0 && limit > e.Cap {
+		limit = e.Cap
+	}
+	if e.OnEstimate != nil {
+		e.OnEstimate(msg, estimated, limit)
+	}
+	return limit, nil
+}
+
+// Wrap 返回一个 EstimateGas 走本估算器的合约后端，供 abigen 绑定使用
+func (e *GasEstimator) Wrap(backend bind.ContractBackend) bind.ContractBackend {
+	return &estimatingBackend{ContractBackend: backend, estimator: e}
+}
+
+type estimatingBackend struct {
+	bind.ContractBackend
+	estimator *GasEstimator
+}
+
+func (b *estimatingBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return b.estimator.Estimate(ctx, msg)
+}
+
+// PendingCallContract 转发给支持 pending 状态的后端，包装后 counter get -pending 仍然可用
+func (b *estimatingBackend) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
+	pending, ok := b.ContractBackend.(bind.PendingContractCaller)
+	if !ok {
+		return nil, bind.ErrNoPendingState
+	}
+	return pending.PendingCallContract(ctx, call)
+}
@@ -0,0 +1,309 @@
+package txmgr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NonceBackend 同步 nonce 所需的节点接口
+type NonceBackend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// InflightTx 已发送、尚未确认的交易
+type InflightTx struct {
+	Hash     common.Hash   `json:"hash"`
+	Sent     time.Time     `json:"sent"`
+	Replaced []common.Hash `json:"replaced,omitempty"` // 被 Hash 替换掉的同 nonce 交易
+}
+
+// Hashes 返回当前交易及其替换过的所有交易
+func (tx InflightTx) Hashes() []common.Hash {
+	return append([]common.Hash{tx.Hash}, tx.Replaced...)
+}
+
+// nonceState 单个 (chainID, 地址) 的 nonce 状态，只有 Inflight 会持久化
+type nonceState struct {
+	Inflight map[uint64]InflightTx `json:"inflight"`
+
+	synced    bool
+	next      uint64              // 下一个未分配的 nonce
+	released  []uint64            // 分配后未发送成功、可以复用的 nonce
+	allocated map[uint64]struct{} // 已分配、尚未 Sent 或 Release 的 nonce，Resync 后仍然占用
+}
+
+// NonceManager 为同一账户的并发发送分配连续的 nonce。
+// 在途交易写入本地文件，重启后不会重复使用尚未上链的 nonce。
+// 文件不做跨进程加锁，同一账户同时只应有一个进程使用。
+type NonceManager struct {
+	backend NonceBackend
+	path    string
+
+	// Expiry 在途交易超过该时间仍未上链时视为已被丢弃，同步时不再占用其 nonce
+	Expiry time.Duration
+
+	mu       sync.Mutex
+	accounts map[string]*nonceState
+}
+
+// NewNonceManager 创建 NonceManager，path 为空时不持久化
+func NewNonceManager(backend NonceBackend, path string) (*NonceManager, error) {
+	m := &NonceManager{
+		backend:  backend,
+		path:     path,
+		Expiry:   30 * time.Minute,
+		accounts: make(map[string]*nonceState),
+	}
+	if path == "" {
+		return m, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &m.accounts); err != nil {
+		return nil, fmt.Errorf("parse nonce file %s: %w", path, err)
+	}
+	return m, nil
+}
+
+func nonceKey(chainID *big.Int, from common.Address) string {
+	return chainID.String() + "/" + from.Hex()
+}
+
+func (m *NonceManager) state(chainID *big.Int, from common.Address) *nonceState {
+	key := nonceKey(chainID, from)
+	st, ok := m.accounts[key]
+	if !ok {
+		st = new(nonceState)
+		m.accounts[key] = st
+	}
+	if st.Inflight == nil {
+		st.Inflight = make(map[uint64]InflightTx)
+	}
+	if st.allocated == nil {
+		st.allocated = make(map[uint64]struct{})
+	}
+	return st
+}
+
+// Next 分配下一个 nonce，优先复用之前归还的 nonce
+func (m *NonceManager) Next(ctx context.Context, chainID *big.Int, from common.Address) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := m.state(chainID, from)
+	if !st.synced {
+		if err := m.sync(ctx, st, from); err != nil {
+			return 0, err
+		}
+	}
+	var nonce uint64
+	if len(st.released) > 0 {
+		nonce, st.released = st.released[0], st.released[1:]
+	} else {
+		nonce = st.next
+		st.next++
+	}
+	st.allocated[nonce] = struct{}{}
+	return nonce, nil
+}
+
+// sync 以节点为准重新计算下一个 nonce，并清理已上链或过期的在途记录。
+// 节点的 pending nonce 到最高占用的 nonce 之间既不在途、也没有被分配出去的 nonce
+// 视为已归还，优先复用：否则中间缺一个 nonce，后面的交易会一直卡在交易池里。
+func (m *NonceManager) sync(ctx context.Context, st *nonceState, from common.Address) error {
+	pending, err := m.backend.PendingNonceAt(ctx, from)
+	if err != nil {
+		return err
+	}
+	confirmed, err := m.backend.NonceAt(ctx, from, nil)
+	if err != nil {
+		return err
+	}
+	next := pending
+	for nonce, tx := range st.Inflight {
+		if nonce < confirmed || (m.Expiry > 0 && time.Since(tx.Sent) > m.Expiry) {
+			delete(st.Inflight, nonce)
+			continue
+		}
+		next = max(next, nonce+1)
+	}
+	for nonce := range st.allocated {
+		next = max(next, nonce+1)
+	}
+	st.released = nil
+	for nonce := pending; nonce < next; nonce++ {
+		_, inflight := st.Inflight[nonce]
+		_, allocated := st.allocated[nonce]
+		if !inflight && !allocated {
+			st.released = append(st.released, nonce)
+		}
+	}
+	st.next, st.synced = next, true
+	return m.save()
+}
+
+// Sent 记录已发送的交易
+func (m *NonceManager) Sent(chainID *big.Int, from common.Address, tx *types.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := m.state(chainID, from)
+	delete(st.allocated, tx.Nonce())
+	st.Inflight[tx.Nonce()] = InflightTx{Hash: tx.Hash(), Sent: time.Now()}
+	return m.save()
+}
+
+// Replaced 记录用相同 nonce 发送的替换交易，保留被替换交易的哈希
+func (m *NonceManager) Replaced(chainID *big.Int, from common.Address, old common.Hash, tx *types.Transaction) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := m.state(chainID, from)
+	prev := st.Inflight[tx.Nonce()]
+	replaced := []common.Hash{old}
+	for _, h := range prev.Hashes() {
+		if h != old && h != (common.Hash{}) {
+			replaced = append(replaced, h)
+		}
+	}
+	st.Inflight[tx.Nonce()] = InflightTx{Hash: tx.Hash(), Sent: time.Now(), Replaced: replaced}
+	return m.save()
+}
+
+// Release 归还一个分配后没有成功发送的 nonce
+func (m *NonceManager) Release(chainID *big.Int, from common.Address, nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := m.state(chainID, from)
+	delete(st.allocated, nonce)
+	if !st.synced {
+		return // 下次同步时作为空缺复用
+	}
+	st.released = append(st.released, nonce)
+	sort.Slice(st.released, func(i, j int) bool { return st.released[i] < st.released[j] })
+	// 归还的是末尾的 nonce 时直接回退计数
+	for n := len(st.released); n > 0 && st.released[n-1]+1 == st.next; n-- {
+		st.released = st.released[:n-1]
+		st.next--
+	}
+}
+
+// Resync 丢弃本地计数，下次分配时重新向节点查询；已分配尚未发送的 nonce 仍然占用
+func (m *NonceManager) Resync(chainID *big.Int, from common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state(chainID, from).synced = false
+}
+
+// Inflight 返回某账户的在途交易
+func (m *NonceManager) Inflight(chainID *big.Int, from common.Address) map[uint64]InflightTx {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[uint64]InflightTx)
+	for nonce, tx := range m.state(chainID, from).Inflight {
+		tx.Replaced = append([]common.Hash(nil), tx.Replaced...)
+		out[nonce] = tx
+	}
+	return out
+}
+
+// Send 分配 nonce 并调用 send 发送交易，send 返回 nil, nil 表示无需发送，nonce 直接归还。
+// 遇到 nonce 冲突时重新同步并重试一次；确定没有发出的失败（见 NotSent）归还 nonce。
+// 超时、连接中断等无法确定节点是否收到交易的失败不直接复用这个 nonce，而是在下次分配前
+// 重新查询节点的 pending nonce：节点已收到这笔交易时，它的 nonce 不会再分配出去。
+func (m *NonceManager) Send(ctx context.Context, chainID *big.Int, from common.Address, send func(nonce uint64) (*types.Transaction, error)) (*types.Transaction, error) {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var nonce uint64
+		if nonce, err = m.Next(ctx, chainID, from); err != nil {
+			return nil, err
+		}
+		var tx *types.Transaction
+		tx, err = send(nonce)
+		switch {
+		case err == nil && tx == nil:
+			m.Release(chainID, from, nonce)
+			return nil, nil
+		case err == nil:
+			return tx, m.Sent(chainID, from, tx)
+		case NotSent(err):
+			m.Release(chainID, from, nonce)
+			return nil, err
+		}
+		// 先 Resync 再归还：这个 nonce 可能已被占用，不应作为空缺直接复用，同步时以节点为准
+		m.Resync(chainID, from)
+		m.Release(chainID, from, nonce)
+		if !IsNonceError(err) {
+			if tx != nil {
+				return nil, fmt.Errorf("transaction %s may have been sent: %w", tx.Hash().Hex(), err)
+			}
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// NotSent 判断发送失败时交易是否确定没有发出：发送前的模拟或 gas 估算失败，或者节点明确拒绝
+// （返回了 JSON-RPC 错误，nonce 冲突除外）。超时、连接中断、HTTP 错误等无法确定。
+func NotSent(err error) bool {
+	if err == nil || IsNonceError(err) {
+		return false
+	}
+	var (
+		simErr *SimulationError
+		capErr *GasCapError
+		rpcErr rpc.Error
+	)
+	return errors.As(err, &simErr) || errors.As(err, &capErr) || errors.Is(err, bind.ErrNoCode) || errors.As(err, &rpcErr)
+}
+
+// IsNonceError 判断发送失败是否因为 nonce 已被占用
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "replacement transaction underpriced")
+}
+
+// save 把在途交易原子地写入文件，调用方需持有锁
+func (m *NonceManager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o755); err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
@@ -0,0 +1,253 @@
+package txmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// nonceNode 节点上的 nonce：pending 包括交易池中的交易，confirmed 只算已上链的
+type nonceNode struct {
+	mu                 sync.Mutex
+	pending, confirmed uint64
+}
+
+func (n *nonceNode) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.pending, nil
+}
+
+func (n *nonceNode) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.confirmed, nil
+}
+
+var (
+	testChain = big.NewInt(1337)
+	testFrom  = common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+)
+
+func txWithNonce(nonce uint64) *types.Transaction {
+	return types.NewTx(&types.LegacyTx{Nonce: nonce, Gas: 21000, GasPrice: big.NewInt(1)})
+}
+
+func next(t *testing.T, m *NonceManager) uint64 {
+	t.Helper()
+	nonce, err := m.Next(context.Background(), testChain, testFrom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nonce
+}
+
+func sent(t *testing.T, m *NonceManager, nonce uint64) {
+	t.Helper()
+	if err := m.Sent(testChain, testFrom, txWithNonce(nonce)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNonceConcurrentNext(t *testing.T) {
+	m, err := NewNonceManager(&nonceNode{pending: 7, confirmed: 7}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 50
+	nonces := make([]uint64, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonces[i], _ = m.Next(context.Background(), testChain, testFrom)
+		}()
+	}
+	wg.Wait()
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for i, nonce := range nonces {
+		if nonce != uint64(7+i) {
+			t.Fatalf("nonces %v, want 7..%d without duplicates", nonces, 7+n-1)
+		}
+	}
+}
+
+func TestNonceReleaseGapRefilled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.json")
+	node := &nonceNode{pending: 5, confirmed: 5}
+	m, err := NewNonceManager(node, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b, c := next(t, m), next(t, m), next(t, m)
+	sent(t, m, b)
+	sent(t, m, c)
+	// a 发送失败，b、c 已广播：节点的 pending nonce 停在 a
+	m.Release(testChain, testFrom, a)
+	if got := next(t, m); got != a {
+		t.Errorf("Next after Release = %d, want %d", got, a)
+	}
+
+	// 下次运行从文件恢复在途交易，a 仍是空缺，必须先补上
+	m, err = NewNonceManager(node, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := next(t, m); got != a {
+		t.Errorf("Next after restart = %d, want the gap %d", got, a)
+	}
+	if got := next(t, m); got != c+1 {
+		t.Errorf("Next = %d, want %d", got, c+1)
+	}
+}
+
+func TestNonceResyncKeepsAllocated(t *testing.T) {
+	node := &nonceNode{pending: 5, confirmed: 5}
+	m, err := NewNonceManager(node, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	held := next(t, m) // 另一个 goroutine 持有、尚未发送
+	sent(t, m, next(t, m))
+	// 5 尚未发送，6 在交易池中排队，节点的 pending nonce 仍是 5
+	m.Resync(testChain, testFrom)
+	if got := next(t, m); got == held || got != 7 {
+		t.Errorf("Next after Resync = %d, want 7 (5 is still held)", got)
+	}
+	m.Release(testChain, testFrom, held)
+	if got := next(t, m); got != held {
+		t.Errorf("Next after releasing the held nonce = %d, want %d", got, held)
+	}
+
+	// Send 遇到 nonce 冲突时重新同步，冲突的 nonce 不作为空缺复用
+	node.pending, node.confirmed = 9, 9
+	var tried []uint64
+	tx, err := m.Send(context.Background(), testChain, testFrom, func(nonce uint64) (*types.Transaction, error) {
+		tried = append(tried, nonce)
+		if len(tried) == 1 {
+			return nil, errors.New("nonce too low")
+		}
+		return txWithNonce(nonce), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce() != 9 {
+		t.Errorf("Send after nonce conflict used %d (tried %v), want 9", tx.Nonce(), tried)
+	}
+}
+
+// rejected 节点返回的 JSON-RPC 错误
+type rejected string
+
+func (e rejected) Error() string  { return string(e) }
+func (e rejected) ErrorCode() int { return -32000 }
+
+func TestNonceSendUncertain(t *testing.T) {
+	node := &nonceNode{pending: 3, confirmed: 3}
+	m, err := NewNonceManager(node, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(err error, received bool) {
+		t.Helper()
+		tx, serr := m.Send(context.Background(), testChain, testFrom, func(nonce uint64) (*types.Transaction, error) {
+			if received {
+				node.mu.Lock()
+				node.pending = nonce + 1
+				node.mu.Unlock()
+			}
+			return nil, err
+		})
+		if tx != nil || !errors.Is(serr, err) {
+			t.Fatalf("Send = %v, %v, want nil, %v", tx, serr, err)
+		}
+	}
+
+	// 节点明确拒绝：交易没有发出，nonce 直接复用
+	send(fmt.Errorf("broadcast: %w", rejected("insufficient funds for gas * price + value")), false)
+	if got := next(t, m); got != 3 {
+		t.Errorf("Next after a rejected send = %d, want 3", got)
+	}
+	m.Release(testChain, testFrom, 3)
+
+	// 超时但节点已经收到交易：重新同步后跳过这个 nonce
+	send(context.DeadlineExceeded, true)
+	if got := next(t, m); got != 4 {
+		t.Errorf("Next after a timed-out send the node received = %d, want 4", got)
+	}
+	m.Release(testChain, testFrom, 4)
+
+	// 连接中断且节点没有收到：同步后由节点确认空缺，nonce 仍可使用
+	send(errors.New("connection reset by peer"), false)
+	if got := next(t, m); got != 4 {
+		t.Errorf("Next after a lost send = %d, want 4", got)
+	}
+	m.Release(testChain, testFrom, 4)
+
+	// 已签名的交易在发送时超时：错误中带上交易哈希
+	tx := txWithNonce(4)
+	_, err = m.Send(context.Background(), testChain, testFrom, func(uint64) (*types.Transaction, error) {
+		return tx, context.DeadlineExceeded
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), tx.Hash().Hex()) {
+		t.Errorf("Send error %v, want a timeout mentioning %s", err, tx.Hash().Hex())
+	}
+}
+
+func TestNoncePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.json")
+	node := &nonceNode{pending: 3, confirmed: 3}
+	m, err := NewNonceManager(node, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		sent(t, m, next(t, m))
+	}
+	bumped := types.NewTx(&types.LegacyTx{Nonce: 4, Gas: 21000, GasPrice: big.NewInt(2)})
+	if err := m.Replaced(testChain, testFrom, txWithNonce(4).Hash(), bumped); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err = NewNonceManager(node, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inflight := m.Inflight(testChain, testFrom)
+	if len(inflight) != 3 || inflight[3].Hash != txWithNonce(3).Hash() || inflight[4].Hash != bumped.Hash() || len(inflight[4].Replaced) != 1 {
+		t.Fatalf("inflight after reload %+v", inflight)
+	}
+	// 3 已上链，4、5 仍在交易池
+	node.pending, node.confirmed = 6, 4
+	if got := next(t, m); got != 6 {
+		t.Errorf("Next = %d, want 6", got)
+	}
+	if inflight := m.Inflight(testChain, testFrom); len(inflight) != 2 {
+		t.Errorf("confirmed nonce not pruned: %+v", inflight)
+	}
+
+	// 过期的在途交易视为已被丢弃，不再占用 nonce
+	m, err = NewNonceManager(&nonceNode{pending: 4, confirmed: 4}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Expiry = time.Nanosecond
+	if got := next(t, m); got != 4 {
+		t.Errorf("Next with expired inflight = %d, want 4", got)
+	}
+	if inflight := m.Inflight(testChain, testFrom); len(inflight) != 0 {
+		t.Errorf("expired inflight not pruned: %+v", inflight)
+	}
+}
@@ -0,0 +1,96 @@
+package txmgr
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// DefaultPriceBump 替换交易默认的加价百分比。geth 交易池要求至少 10%，
+// 部分客户端要求 12.5%，取后者两边都能接受。
+const DefaultPriceBump = 12.5
+
+// MinPriceBump 替换交易允许的最小加价百分比
+const MinPriceBump = 10
+
+// ErrPriceBumpTooLow 加价比例低于交易池的替换要求
+var ErrPriceBumpTooLow = errors.New("price bump must be at least 10%")
+
+// BumpFees 计算替换 old 所需的手续费：原手续费按 percent 加价并向上取整，
+// 且不低于当前建议值。原交易是 legacy 时保持 legacy。
+func BumpFees(old *types.Transaction, suggested Fees, percent float64) (Fees, error) {
+	if percent < MinPriceBump {
+		return Fees{}, ErrPriceBumpTooLow
+	}
+	if old.Type() == types.LegacyTxType || old.Type() == types.AccessListTxType {
+		floor := suggested.GasPrice
+		if floor == nil {
+			floor = suggested.GasFeeCap
+		}
+		return Fees{GasPrice: maxBig(bump(old.GasPrice(), percent), floor)}, nil
+	}
+	tip := maxBig(bump(old.GasTipCap(), percent), suggested.GasTipCap)
+	feeCap := maxBig(bump(old.GasFeeCap(), percent), suggested.GasFeeCap)
+	if feeCap.Cmp(tip) < 0 {
+		feeCap = new(big.Int).Set(tip)
+	}
+	return Fees{GasTipCap: tip, GasFeeCap: feeCap}, nil
+}
+
+// SpeedUp 构造与 old 内容相同（包括访问列表）、手续费更高的替换交易（未签名）
+func SpeedUp(old *types.Transaction, fees Fees, chainID *big.Int) *types.Transaction {
+	accessList := old.AccessList()
+	if len(accessList) == 0 {
+		return fees.NewTx(chainID, old.Nonce(), old.To(), old.Value(), old.Gas(), old.Data())
+	}
+	if !fees.Dynamic() {
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      old.Nonce(),
+			GasPrice:   fees.GasPrice,
+			Gas:        old.Gas(),
+			To:         old.To(),
+			Value:      old.Value(),
+			Data:       old.Data(),
+			AccessList: accessList,
+		})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    chainID,
+		Nonce:      old.Nonce(),
+		GasTipCap:  fees.GasTipCap,
+		GasFeeCap:  fees.GasFeeCap,
+		Gas:        old.Gas(),
+		To:         old.To(),
+		Value:      old.Value(),
+		Data:       old.Data(),
+		AccessList: accessList,
+	})
+}
+
+// Cancel 构造占用 old 的 nonce、向自己转 0 的替换交易（未签名）
+func Cancel(old *types.Transaction, from common.Address, fees Fees, chainID *big.Int) *types.Transaction {
+	return fees.NewTx(chainID, old.Nonce(), &from, new(big.Int), params.TxGas, nil)
+}
+
+// bump 返回 v * (100 + percent) / 100，向上取整
+func bump(v *big.Int, percent float64) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	// 以千分之一为单位，避免浮点误差
+	num := big.NewInt(100000 + int64(percent*1000))
+	out := new(big.Int).Mul(v, num)
+	out.Add(out, big.NewInt(100000-1))
+	return out.Div(out, big.NewInt(100000))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if b == nil || a.Cmp(b) >= 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
@@ -0,0 +1,38 @@
+package txmgr
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSpeedUpKeepsAccessList(t *testing.T) {
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{{1}}}}
+	olds := []*types.Transaction{
+		types.NewTx(&types.DynamicFeeTx{
+			ChainID: testChain, Nonce: 3, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9),
+			Gas: 50000, To: &to, Data: []byte{1}, AccessList: accessList,
+		}),
+		types.NewTx(&types.AccessListTx{
+			ChainID: testChain, Nonce: 3, GasPrice: big.NewInt(2e9),
+			Gas: 50000, To: &to, Data: []byte{1}, AccessList: accessList,
+		}),
+	}
+	for _, old := range olds {
+		fees, err := BumpFees(old, Fees{GasPrice: big.NewInt(1), GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1)}, DefaultPriceBump)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx := SpeedUp(old, fees, testChain)
+		if tx.Type() != old.Type() || tx.Nonce() != old.Nonce() || len(tx.AccessList()) != 1 ||
+			tx.AccessList()[0].Address != to || tx.AccessList()[0].StorageKeys[0] != accessList[0].StorageKeys[0] {
+			t.Errorf("SpeedUp of type %d = type %d with access list %v, want %v", old.Type(), tx.Type(), tx.AccessList(), accessList)
+		}
+		if tx.GasFeeCap().Cmp(old.GasFeeCap()) <= 0 {
+			t.Errorf("SpeedUp of type %d did not bump fees: %v -> %v", old.Type(), old.GasFeeCap(), tx.GasFeeCap())
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package txmgr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"sepolia-block/revert"
+)
+
+// SimulationError 发送前的模拟执行失败，交易没有发出。
+// Err 通常是 revert 包中的错误类型，可以继续用 errors.As 判断回滚原因。
+type SimulationError struct {
+	Tx  *types.Transaction // 已签名但未发送的交易
+	Err error
+}
+
+func (e *SimulationError) Error() string {
+	return fmt.Sprintf("simulation of transaction %s failed: %v", e.Tx.Hash().Hex(), e.Err)
+}
+
+func (e *SimulationError) Unwrap() error { return e.Err }
+
+type simulateKey struct{}
+
+// WithSimulation 为单次调用打开或关闭发送前模拟，优先于 Simulator.Enabled。
+// 用法：opts.Context = txmgr.WithSimulation(ctx, false)
+func WithSimulation(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, simulateKey{}, enabled)
+}
+
+// Simulator 在发送交易前，以相同的 from、to、value、data、gas 和手续费
+// 在 pending 区块上执行 eth_call，模拟失败时不发送，避免为必然回滚的交易支付 gas。
+type Simulator struct {
+	backend bind.PendingContractCaller
+	decoder *revert.Decoder
+
+	Enabled bool // 全局开关，单次调用可用 WithSimulation 覆盖
+}
+
+// NewSimulator 创建默认开启的 Simulator，回滚数据用 decoder 解码（为 nil 时只识别标准错误）
+func NewSimulator(backend bind.PendingContractCaller, decoder *revert.Decoder) *Simulator {
+	if decoder == nil {
+		decoder = &revert.Decoder{}
+	}
+	return &Simulator{backend: backend, decoder: decoder, Enabled: true}
+}
+
+// enabled 判断本次调用是否需要模拟
+func (s *Simulator) enabled(ctx context.Context) bool {
+	if v, ok := ctx.Value(simulateKey{}).(bool); ok {
+		return v
+	}
+	return s.Enabled
+}
+
+// Simulate 在 pending 区块上模拟已签名的交易，失败时返回 *SimulationError
+func (s *Simulator) Simulate(ctx context.Context, tx *types.Transaction) error {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		msg.GasPrice = tx.GasPrice()
+	} else {
+		msg.GasFeeCap = tx.GasFeeCap()
+		msg.GasTipCap = tx.GasTipCap()
+	}
+	if _, err := s.backend.PendingCallContract(ctx, msg); err != nil {
+		return &SimulationError{Tx: tx, Err: s.decoder.Decode(err)}
+	}
+	return nil
+}
+
+// Wrap 返回一个发送前先模拟的合约后端，abigen 绑定的 Inc、IncBy 和 Transact 都经过 SendTransaction
+func (s *Simulator) Wrap(backend bind.ContractBackend) bind.ContractBackend {
+	return &simulatingBackend{ContractBackend: backend, simulator: s}
+}
+
+type simulatingBackend struct {
+	bind.ContractBackend
+	simulator *Simulator
+}
+
+func (b *simulatingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if b.simulator.enabled(ctx) {
+		if err := b.simulator.Simulate(ctx, tx); err != nil {
+			return err
+		}
+	}
+	return b.ContractBackend.SendTransaction(ctx, tx)
+}
+
+// PendingCallContract 转发给支持 pending 状态的后端
+func (b *simulatingBackend) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
+	pending, ok := b.ContractBackend.(bind.PendingContractCaller)
+	if !ok {
+		return nil, bind.ErrNoPendingState
+	}
+	return pending.PendingCallContract(ctx, call)
+}
@@ -0,0 +1,186 @@
+// Package txmgr 管理交易从构造、发送到确认的整个生命周期。
+package txmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ReceiptBackend 跟踪交易所需的节点接口
+type ReceiptBackend interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// RevertedError 交易已上链但执行失败（status = 0）
+type RevertedError struct {
+	Receipt *types.Receipt
+}
+
+func (e *RevertedError) Error() string {
+	return fmt.Sprintf("transaction %s reverted in block %d", e.Receipt.TxHash.Hex(), e.Receipt.BlockNumber)
+}
+
+// Progress 等待过程中的状态变化
+type Progress struct {
+	TxHash        common.Hash
+	Confirmations uint64 // 0 表示尚未打包
+	Reorged       bool   // 之前看到的回执因重组失效
+}
+
+// Result 达到确认数后的交易结果
+type Result struct {
+	Receipt           *types.Receipt
+	Confirmations     uint64
+	Reorgs            int      // 等待期间检测到的重组次数
+	GasUsed           uint64   // 实际消耗的 gas
+	EffectiveGasPrice *big.Int // 实际 gas 单价
+	Fee               *big.Int // GasUsed * EffectiveGasPrice
+}
+
+// Tracker 轮询交易回执直到达到指定确认数
+type Tracker struct {
+	backend ReceiptBackend
+
+	Confirmations uint64         // 需要的确认数，打包所在区块算 1 个
+	PollInterval  time.Duration  // 轮询间隔
+	OnProgress    func(Progress) // 可选，状态变化时回调
+}
+
+// NewTracker 创建 Tracker，默认 1 个确认、每 2 秒轮询
+func NewTracker(backend ReceiptBackend) *Tracker {
+	return &Tracker{backend: backend, Confirmations: 1, PollInterval: 2 * time.Second}
+}
+
+// Wait 等待交易达到确认数。执行失败的交易返回 *RevertedError，
+// 同时返回的 Result 仍包含回执和 gas 信息。
+func (t *Tracker) Wait(ctx context.Context, hash common.Hash) (*Result, error) {
+	return t.WaitAny(ctx, hash)
+}
+
+// WaitAny 等待同一 nonce 的一组交易（原交易及其替换交易）中任意一笔达到确认数，
+// 通过 Result.Receipt.TxHash 可知最终上链的是哪一笔。
+func (t *Tracker) WaitAny(ctx context.Context, hashes ...common.Hash) (*Result, error) {
+	if len(hashes) == 0 {
+		return nil, errors.New("no transaction to wait for")
+	}
+	var (
+		seen     *types.Receipt // 最近一次看到的回执
+		reorgs   int
+		reported uint64
+	)
+	ticker := time.NewTicker(t.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := t.receipt(ctx, hashes)
+		switch {
+		case err != nil:
+			return nil, err
+		case receipt == nil:
+			if seen != nil {
+				// 回执消失：所在区块被重组掉，交易回到交易池
+				reorgs++
+				t.progress(Progress{TxHash: seen.TxHash, Reorged: true})
+				seen, reported = nil, 0
+			}
+		default:
+			if seen != nil && (seen.BlockHash != receipt.BlockHash || seen.TxHash != receipt.TxHash) {
+				reorgs++
+				reported = 0
+				t.progress(Progress{TxHash: seen.TxHash, Reorged: true})
+			}
+			seen = receipt
+
+			confs, canonical, err := t.confirmations(ctx, receipt)
+			if err != nil {
+				return nil, err
+			}
+			if !canonical {
+				// 节点的回执索引还没跟上重组，下一轮再查
+				break
+			}
+			if confs != reported {
+				reported = confs
+				t.progress(Progress{TxHash: receipt.TxHash, Confirmations: confs})
+			}
+			if confs >= t.Confirmations {
+				return t.result(receipt, confs, reorgs)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// receipt 返回 hashes 中第一笔已打包交易的回执，都未打包时返回 nil
+func (t *Tracker) receipt(ctx context.Context, hashes []common.Hash) (*types.Receipt, error) {
+	for _, hash := range hashes {
+		receipt, err := t.backend.TransactionReceipt(ctx, hash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return receipt, nil
+	}
+	return nil, nil
+}
+
+// confirmations 计算确认数，并检查回执所在区块是否仍在主链上
+func (t *Tracker) confirmations(ctx context.Context, receipt *types.Receipt) (uint64, bool, error) {
+	header, err := t.backend.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	if header.Hash() != receipt.BlockHash {
+		return 0, false, nil
+	}
+	head, err := t.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	if head.Number.Cmp(receipt.BlockNumber) < 0 {
+		return 0, true, nil
+	}
+	return new(big.Int).Sub(head.Number, receipt.BlockNumber).Uint64() + 1, true, nil
+}
+
+func (t *Tracker) result(receipt *types.Receipt, confs uint64, reorgs int) (*Result, error) {
+	price := receipt.EffectiveGasPrice
+	if price == nil {
+		price = new(big.Int)
+	}
+	res := &Result{
+		Receipt:           receipt,
+		Confirmations:     confs,
+		Reorgs:            reorgs,
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: price,
+		Fee:               new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), price),
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		return res, &RevertedError{Receipt: receipt}
+	}
+	return res, nil
+}
+
+func (t *Tracker) progress(p Progress) {
+	if t.OnProgress != nil {
+		t.OnProgress(p)
+	}
+}
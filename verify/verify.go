@@ -0,0 +1,294 @@
+// Package verify 确认某地址上的合约代码与绑定中嵌入的字节码一致，
+// 避免 NewCounter 之类的绑定指向一个并非 Counter 的合约。
+//
+// 比较对象是创建字节码中的运行时部分：去掉末尾 solc 追加的 CBOR 元数据
+// （其中的源码哈希随注释、路径变化，不影响代码行为），并把部署时才填入的
+// immutable 占位（值为零的 PUSH32）视为通配。
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// CodeBackend 读取合约代码所需的节点接口
+type CodeBackend interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// Status 核对结论
+type Status int
+
+const (
+	NoCode       Status = iota // 地址上没有代码
+	Mismatch                   // 代码不一致
+	PartialMatch               // 代码一致，元数据（源码哈希或编译器版本）不同
+	FullMatch                  // 代码和元数据都一致
+)
+
+func (s Status) String() string {
+	switch s {
+	case NoCode:
+		return "no_code"
+	case Mismatch:
+		return "mismatch"
+	case PartialMatch:
+		return "partial_match"
+	case FullMatch:
+		return "full_match"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// OK 代码一致（不要求元数据一致）
+func (s Status) OK() bool {
+	return s == PartialMatch || s == FullMatch
+}
+
+// Immutable 部署时填入运行时代码的 immutable 值
+type Immutable struct {
+	Offset int // 在运行时代码中的字节偏移
+	Value  common.Hash
+}
+
+// Result 核对结果
+type Result struct {
+	Address    common.Address
+	Status     Status
+	Expected   *Metadata // 嵌入字节码的元数据，没有时为 nil
+	Deployed   *Metadata // 链上代码的元数据，没有时为 nil
+	CodeSize   int       // 链上代码长度
+	Immutables []Immutable
+}
+
+// Verify 读取 address 在 blockNumber（nil 表示最新区块）的代码，与创建字节码 bin 的运行时部分比较
+func Verify(ctx context.Context, backend CodeBackend, address common.Address, bin string, blockNumber *big.Int) (*Result, error) {
+	creation, err := hexutil.Decode(bin)
+	if err != nil {
+		return nil, fmt.Errorf("decode bytecode: %w", err)
+	}
+	runtime, err := RuntimeCode(creation)
+	if err != nil {
+		return nil, err
+	}
+	code, err := backend.CodeAt(ctx, address, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	res := Compare(runtime, code)
+	res.Address = address
+	return res, nil
+}
+
+// Compare 比较期望的运行时代码 expected 与链上代码 deployed
+func Compare(expected, deployed []byte) *Result {
+	wantBody, wantMeta := SplitMetadata(expected)
+	gotBody, gotMeta := SplitMetadata(deployed)
+	res := &Result{Expected: wantMeta, Deployed: gotMeta, CodeSize: len(deployed)}
+	switch {
+	case len(deployed) == 0:
+		res.Status = NoCode
+		return res
+	case len(wantBody) != len(gotBody):
+		res.Status = Mismatch
+		return res
+	}
+
+	slots := immutableSlots(wantBody)
+	for i := 0; i < len(wantBody); i++ {
+		if end, ok := slots[i]; ok {
+			res.Immutables = append(res.Immutables, Immutable{Offset: i, Value: common.BytesToHash(gotBody[i:end])})
+			i = end - 1
+			continue
+		}
+		if wantBody[i] != gotBody[i] {
+			res.Status = Mismatch
+			res.Immutables = nil
+			return res
+		}
+	}
+	if wantMeta != nil && gotMeta != nil && bytes.Equal(wantMeta.Raw, gotMeta.Raw) {
+		res.Status = FullMatch
+	} else {
+		res.Status = PartialMatch
+	}
+	return res
+}
+
+// RuntimeCode 从 solc 生成的创建字节码中取出运行时代码。
+// 构造代码以 RETURN 结束、随后是一个 INVALID（0xfe）分隔符，运行时代码紧随其后。
+func RuntimeCode(creation []byte) ([]byte, error) {
+	for pc := 0; pc < len(creation); pc++ {
+		op := vm.OpCode(creation[pc])
+		if op.IsPush() {
+			pc += int(op - vm.PUSH0)
+			continue
+		}
+		if op == vm.RETURN && pc+1 < len(creation) && vm.OpCode(creation[pc+1]) == vm.INVALID {
+			return creation[pc+2:], nil
+		}
+	}
+	return nil, errors.New("cannot locate runtime code in creation bytecode")
+}
+
+// immutableSlots 返回运行时代码中 immutable 占位的区间（起始偏移 → 结束偏移）。
+// solc 在运行时代码中用数据全为零的 PUSH32 为 immutable 占位，部署时由构造代码写入实际值。
+func immutableSlots(code []byte) map[int]int {
+	slots := make(map[int]int)
+	for pc := 0; pc < len(code); pc++ {
+		op := vm.OpCode(code[pc])
+		if !op.IsPush() {
+			continue
+		}
+		n := int(op - vm.PUSH0)
+		if op == vm.PUSH32 && pc+1+n <= len(code) && isZero(code[pc+1:pc+1+n]) {
+			slots[pc+1] = pc + 1 + n
+		}
+		pc += n
+	}
+	return slots
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Metadata solc 追加在运行时代码末尾的 CBOR 元数据
+type Metadata struct {
+	Raw          []byte // 完整的 CBOR 数据
+	Compiler     string // 编译器版本，例如 0.8.33；未记录时为空
+	IPFS         []byte // 元数据 JSON 的 IPFS 哈希
+	Bzzr         []byte // 旧版本使用的 Swarm 哈希
+	Experimental bool
+}
+
+// SplitMetadata 把代码拆成正文和末尾的 CBOR 元数据。
+// 代码末尾两个字节是元数据长度（大端），解析失败时视为没有元数据。
+func SplitMetadata(code []byte) ([]byte, *Metadata) {
+	if len(code) < 2 {
+		return code, nil
+	}
+	n := int(binary.BigEndian.Uint16(code[len(code)-2:]))
+	if n == 0 || n+2 > len(code) {
+		return code, nil
+	}
+	raw := code[len(code)-2-n : len(code)-2]
+	meta, err := parseMetadata(raw)
+	if err != nil {
+		return code, nil
+	}
+	return code[:len(code)-2-n], meta
+}
+
+// parseMetadata 解析 solc 元数据使用的 CBOR 子集：文本键映射到字节串、文本或布尔值
+func parseMetadata(raw []byte) (*Metadata, error) {
+	r := &cborReader{data: raw}
+	major, count, err := r.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != 5 {
+		return nil, errors.New("metadata is not a CBOR map")
+	}
+	meta := &Metadata{Raw: raw}
+	for range count {
+		major, n, err := r.head()
+		if err != nil {
+			return nil, err
+		}
+		if major != 3 {
+			return nil, errors.New("metadata key is not a string")
+		}
+		key, err := r.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		major, n, err = r.head()
+		if err != nil {
+			return nil, err
+		}
+		var value []byte
+		switch major {
+		case 2, 3:
+			if value, err = r.bytes(n); err != nil {
+				return nil, err
+			}
+		case 7: // 简单值：20 false，21 true
+			value = nil
+		default:
+			return nil, fmt.Errorf("unsupported CBOR major type %d", major)
+		}
+		switch string(key) {
+		case "solc":
+			if major == 2 && len(value) == 3 {
+				meta.Compiler = fmt.Sprintf("%d.%d.%d", value[0], value[1], value[2])
+			} else {
+				meta.Compiler = string(value) // 预发布版本记录完整的版本字符串
+			}
+		case "ipfs":
+			meta.IPFS = value
+		case "bzzr0", "bzzr1":
+			meta.Bzzr = value
+		case "experimental":
+			meta.Experimental = n == 21
+		}
+	}
+	if r.pos != len(raw) {
+		return nil, errors.New("trailing data after metadata")
+	}
+	return meta, nil
+}
+
+type cborReader struct {
+	data []byte
+	pos  int
+}
+
+// head 读取数据项的主类型和参数（长度、元素个数或简单值）
+func (r *cborReader) head() (major byte, arg int, err error) {
+	if r.pos >= len(r.data) {
+		return 0, 0, errors.New("unexpected end of CBOR data")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	major, info := b>>5, int(b&0x1f)
+	switch {
+	case info < 24:
+		return major, info, nil
+	case info == 24:
+		v, err := r.bytes(1)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, int(v[0]), nil
+	case info == 25:
+		v, err := r.bytes(2)
+		if err != nil {
+			return 0, 0, err
+		}
+		return major, int(binary.BigEndian.Uint16(v)), nil
+	}
+	return 0, 0, fmt.Errorf("unsupported CBOR length encoding %d", info)
+}
+
+func (r *cborReader) bytes(n int) ([]byte, error) {
+	if r.pos+n > len(r.data) {
+		return nil, errors.New("unexpected end of CBOR data")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
@@ -0,0 +1,113 @@
+package verify
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"sepolia-block/counter"
+)
+
+func counterRuntime(t *testing.T) []byte {
+	t.Helper()
+	runtime, err := RuntimeCode(hexutil.MustDecode(counter.CounterMetaData.Bin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runtime
+}
+
+func TestRuntimeCode(t *testing.T) {
+	creation := hexutil.MustDecode(counter.CounterMetaData.Bin)
+	runtime := counterRuntime(t)
+	// 构造代码 CODECOPY 的偏移为 0x1c，长度为 0x3cf
+	if len(runtime) != 0x3cf || !bytes.Equal(runtime, creation[0x1c:]) {
+		t.Fatalf("runtime code has %d bytes at offset %d, want 0x3cf at 0x1c", len(runtime), len(creation)-len(runtime))
+	}
+	if _, err := RuntimeCode([]byte{0x60, 0xf3, 0x60, 0xfe}); err == nil {
+		t.Error("RETURN/INVALID inside push data was taken as the boundary")
+	}
+}
+
+func TestSplitMetadata(t *testing.T) {
+	runtime := counterRuntime(t)
+	body, meta := SplitMetadata(runtime)
+	if meta == nil {
+		t.Fatal("no metadata found")
+	}
+	if meta.Compiler != "0.8.33" {
+		t.Errorf("compiler %q, want 0.8.33", meta.Compiler)
+	}
+	if len(meta.IPFS) != 34 {
+		t.Errorf("ipfs hash has %d bytes, want 34", len(meta.IPFS))
+	}
+	if len(body)+len(meta.Raw)+2 != len(runtime) {
+		t.Errorf("body %d + metadata %d + 2 != %d", len(body), len(meta.Raw), len(runtime))
+	}
+
+	// 末尾长度字段不指向合法的 CBOR 映射时视为没有元数据
+	plain := []byte{0x60, 0x00, 0x00, 0x01}
+	if body, meta := SplitMetadata(plain); meta != nil || !bytes.Equal(body, plain) {
+		t.Errorf("SplitMetadata(%x) = %x, %v", plain, body, meta)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	runtime := counterRuntime(t)
+	body, meta := SplitMetadata(runtime)
+
+	otherMeta := bytes.Clone(runtime)
+	otherMeta[len(body)+10] ^= 0xff // 改动 IPFS 哈希
+
+	otherCode := bytes.Clone(runtime)
+	otherCode[len(body)/2] ^= 0xff
+
+	tests := []struct {
+		name     string
+		deployed []byte
+		want     Status
+	}{
+		{"identical", runtime, FullMatch},
+		{"different metadata", otherMeta, PartialMatch},
+		{"without metadata", body, PartialMatch},
+		{"different code", otherCode, Mismatch},
+		{"truncated", runtime[:len(body)-1], Mismatch},
+		{"empty", nil, NoCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Compare(runtime, tt.deployed)
+			if res.Status != tt.want {
+				t.Errorf("status %s, want %s", res.Status, tt.want)
+			}
+		})
+	}
+	if res := Compare(runtime, runtime); res.Expected.Compiler != meta.Compiler || res.Deployed.Compiler != meta.Compiler {
+		t.Errorf("compilers %q/%q, want %q", res.Expected.Compiler, res.Deployed.Compiler, meta.Compiler)
+	}
+}
+
+func TestCompareImmutables(t *testing.T) {
+	// PUSH32 <零占位> PUSH1 0x00 MSTORE，部署后占位被替换为实际值
+	expected := append([]byte{0x7f}, make([]byte, 32)...)
+	expected = append(expected, 0x60, 0x00, 0x52)
+	value := common.HexToHash("0x2a")
+	deployed := bytes.Clone(expected)
+	copy(deployed[1:33], value[:])
+
+	res := Compare(expected, deployed)
+	if res.Status != PartialMatch {
+		t.Fatalf("status %s, want %s", res.Status, PartialMatch)
+	}
+	if len(res.Immutables) != 1 || res.Immutables[0].Offset != 1 || res.Immutables[0].Value != value {
+		t.Errorf("immutables %+v, want one at offset 1 with %s", res.Immutables, value.Hex())
+	}
+
+	// 占位之外的字节不同仍然不一致
+	deployed[len(deployed)-1] = 0x55
+	if res := Compare(expected, deployed); res.Status != Mismatch {
+		t.Errorf("status %s, want %s", res.Status, Mismatch)
+	}
+}